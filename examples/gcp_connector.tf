@@ -10,9 +10,11 @@ terraform {
 provider "wiz" {
   client_id     = "SERVICE_ACCOUNT_CLIENT_ID"
   client_secret = "SERVICE_ACCOUNT_CLIENT_SECRET"
-  # Optional: Uncomment to override default endpoints
-  # api_url       = "https://api.eu1.demo.wiz.io/graphql"
-  # auth_url      = "https://auth.demo.wiz.io/oauth/token"
+  data_center   = "us1"
+  # Optional: Uncomment to select another environment or override the endpoints
+  # environment   = "commercial"
+  # api_url       = "https://api.us1.app.wiz.io/graphql"
+  # auth_url      = "https://auth.app.wiz.io/oauth/token"
 }
 
 # Create a basic GCP connector
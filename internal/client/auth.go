@@ -0,0 +1,272 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// maxTokenRefreshMargin caps how long before expiry a cached token is refreshed
+const maxTokenRefreshMargin = 5 * time.Minute
+
+// tokenSource obtains new access tokens
+type tokenSource interface {
+	// Token returns a new access token and how long it is valid for
+	Token(ctx context.Context) (string, time.Duration, error)
+}
+
+// Auth providers, selecting the parameters of the token request
+const (
+	// AuthProviderAuto picks the provider from the host of the auth URL
+	AuthProviderAuto    = "auto"
+	AuthProviderCognito = "cognito"
+	AuthProviderAuth0   = "auth0"
+)
+
+// AuthProviders returns the supported auth provider settings
+func AuthProviders() []string {
+	return []string{AuthProviderAuto, AuthProviderCognito, AuthProviderAuth0}
+}
+
+// authAudiences holds the audience each identity provider issues Wiz API tokens for
+var authAudiences = map[string]string{
+	AuthProviderCognito: "wiz-api",
+	AuthProviderAuth0:   "beyond-api",
+}
+
+// auth0Host is the auth host of tenants that still authenticate through Auth0.
+// Every other Wiz auth host is served by Cognito.
+const auth0Host = "auth.wiz.io"
+
+// resolveAuthProvider returns the identity provider of an auth URL, detecting it
+// from the host if the setting is empty or auto
+func resolveAuthProvider(setting string, authURL string) (string, error) {
+	switch setting {
+	case AuthProviderCognito, AuthProviderAuth0:
+		return setting, nil
+	case "", AuthProviderAuto:
+	default:
+		return "", fmt.Errorf("unknown auth provider %q, expected one of %s", setting, strings.Join(AuthProviders(), ", "))
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid auth_url %q: %w", authURL, err)
+	}
+	if host := u.Hostname(); host == auth0Host || strings.HasSuffix(host, ".auth0.com") {
+		return AuthProviderAuth0, nil
+	}
+	return AuthProviderCognito, nil
+}
+
+type accessToken struct {
+	Token   string `json:"access_token"`
+	Expires int    `json:"expires_in"`
+}
+
+// clientCredentialsSource exchanges a service account client ID and secret for
+// an access token using the OAuth client credentials grant
+type clientCredentialsSource struct {
+	httpClient   *http.Client
+	authURL      string
+	audience     string
+	clientID     string
+	clientSecret string
+}
+
+// Token gets a new access token
+func (s *clientCredentialsSource) Token(ctx context.Context) (string, time.Duration, error) {
+	authData := url.Values{}
+	authData.Set("grant_type", "client_credentials")
+	authData.Set("audience", s.audience)
+	authData.Set("client_id", s.clientID)
+	authData.Set("client_secret", s.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.authURL, strings.NewReader(authData.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating authentication request: %w", err)
+	}
+
+	req.Header.Add("Encoding", "UTF-8")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	tflog.SubsystemDebug(ctx, logSubsystemAuth, "Requesting access token", map[string]interface{}{
+		"auth_url": s.authURL,
+		"audience": s.audience,
+	})
+
+	start := time.Now()
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("error authenticating: %w", newTransportError(err))
+	}
+	defer resp.Body.Close()
+
+	tflog.SubsystemDebug(ctx, logSubsystemAuth, "Received authentication response", map[string]interface{}{
+		"status_code": resp.StatusCode,
+		"latency_ms":  time.Since(start).Milliseconds(),
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("error authenticating: %w", &APIError{
+			Kind:       kindFromStatusCode(resp.StatusCode),
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		})
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading authentication response: %w", newTransportError(err))
+	}
+
+	var at accessToken
+	if err := json.Unmarshal(bodyBytes, &at); err != nil {
+		return "", 0, fmt.Errorf("error parsing authentication response: %w", err)
+	}
+
+	return at.Token, time.Duration(at.Expires) * time.Second, nil
+}
+
+// tokenCache caches the token of a tokenSource and is safe for concurrent use.
+// Only one token is fetched at a time; concurrent callers wait for it and share
+// the result. Tokens are refreshed shortly before they expire.
+type tokenCache struct {
+	// mu guards the fields below. It is never held while fetching a token.
+	mu        sync.Mutex
+	source    tokenSource
+	token     string
+	refreshAt time.Time
+	// fetch is the token fetch in flight, if any
+	fetch *tokenFetch
+}
+
+// tokenFetch is a token fetch shared by concurrent callers. done is closed once
+// token and err are set.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newTokenCache(source tokenSource) *tokenCache {
+	return &tokenCache{source: source}
+}
+
+// Token returns the cached token, fetching a new one if it is missing or about
+// to expire. The fetch is retried with backoff on transient errors. Callers
+// waiting for a fetch started by another caller stop waiting when their own
+// context is done.
+func (tc *tokenCache) Token(ctx context.Context, retry func(context.Context, func() error) error) (string, error) {
+	for {
+		tc.mu.Lock()
+
+		// Check if token is still valid
+		if tc.token != "" && time.Now().Before(tc.refreshAt) {
+			token := tc.token
+			tc.mu.Unlock()
+			return token, nil
+		}
+
+		if fetch := tc.fetch; fetch != nil {
+			tc.mu.Unlock()
+
+			select {
+			case <-fetch.done:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+
+			// The caller that started the fetch gave up, so start another one
+			if isContextError(fetch.err) && ctx.Err() == nil {
+				continue
+			}
+			return fetch.token, fetch.err
+		}
+
+		fetch := &tokenFetch{done: make(chan struct{})}
+		tc.fetch = fetch
+		tc.mu.Unlock()
+
+		fetch.token, fetch.err = tc.fetchToken(ctx, retry)
+		close(fetch.done)
+		return fetch.token, fetch.err
+	}
+}
+
+// fetchToken gets a new token from the source and caches it
+func (tc *tokenCache) fetchToken(ctx context.Context, retry func(context.Context, func() error) error) (string, error) {
+	var token string
+	var lifetime time.Duration
+	err := retry(ctx, func() error {
+		var err error
+		token, lifetime, err = tc.source.Token(ctx)
+		return err
+	})
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.fetch = nil
+
+	if err != nil {
+		return "", err
+	}
+
+	// Refresh a tenth of the lifetime early, so a token never expires mid-request
+	margin := lifetime / 10
+	if margin > maxTokenRefreshMargin {
+		margin = maxTokenRefreshMargin
+	}
+
+	tc.token = token
+	tc.refreshAt = time.Now().Add(lifetime - margin)
+
+	if lifetime > 0 {
+		tflog.SubsystemDebug(ctx, logSubsystemAuth, "Cached new access token", map[string]interface{}{
+			"expires_in": lifetime.String(),
+			"refresh_at": tc.refreshAt.Format(time.RFC3339),
+		})
+	}
+
+	return token, nil
+}
+
+// isContextError reports whether an error was caused by a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Invalidate drops the cached token if it is still the given token, so that the
+// next call to Token fetches a new one. Callers that saw the same rejected token
+// concurrently only cause a single refresh.
+func (tc *tokenCache) Invalidate(token string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.token == token {
+		tc.token = ""
+	}
+}
+
+// authenticate returns a valid access token
+func (c *Client) authenticate(ctx context.Context) (string, error) {
+	return c.tokens.Token(ctx, c.retryWithBackoff)
+}
+
+// isUnauthenticated reports whether the API rejected the access token
+func isUnauthenticated(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.Code == "UNAUTHENTICATED"
+}
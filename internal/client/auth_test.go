@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTokenSource returns numbered tokens and blocks each fetch until release
+// is closed
+type fakeTokenSource struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newFakeTokenSource() *fakeTokenSource {
+	return &fakeTokenSource{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (s *fakeTokenSource) Token(ctx context.Context) (string, time.Duration, error) {
+	n := s.calls.Add(1)
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return "", 0, ctx.Err()
+	}
+	return fmt.Sprintf("token-%d", n), time.Hour, nil
+}
+
+func noRetry(ctx context.Context, f func() error) error {
+	return f()
+}
+
+// concurrentTokens calls Token from n goroutines once a fetch has started and
+// every caller is waiting, then returns the tokens they got
+func concurrentTokens(t *testing.T, tc *tokenCache, source *fakeTokenSource, n int) []string {
+	t.Helper()
+
+	tokens := make([]string, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := tc.Token(context.Background(), noRetry)
+			if err != nil {
+				t.Errorf("Token() returned error: %s", err)
+			}
+			tokens[i] = token
+		}(i)
+	}
+
+	<-source.started
+	// Give the other callers time to find the fetch in flight
+	time.Sleep(50 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+
+	return tokens
+}
+
+func TestTokenCacheConcurrentFetch(t *testing.T) {
+	source := newFakeTokenSource()
+	tc := newTokenCache(source)
+
+	tokens := concurrentTokens(t, tc, source, 20)
+
+	if calls := source.calls.Load(); calls != 1 {
+		t.Fatalf("expected 1 fetch, got %d", calls)
+	}
+	for _, token := range tokens {
+		if token != "token-1" {
+			t.Fatalf("expected token-1, got %q", token)
+		}
+	}
+
+	// A cached token is returned without fetching
+	if token, err := tc.Token(context.Background(), noRetry); err != nil || token != "token-1" {
+		t.Fatalf("expected cached token-1, got %q, %v", token, err)
+	}
+	if calls := source.calls.Load(); calls != 1 {
+		t.Fatalf("expected 1 fetch, got %d", calls)
+	}
+}
+
+func TestTokenCacheInvalidate(t *testing.T) {
+	source := newFakeTokenSource()
+	tc := newTokenCache(source)
+
+	concurrentTokens(t, tc, source, 1)
+
+	// Invalidating another token keeps the cached one
+	tc.Invalidate("token-0")
+	if token, err := tc.Token(context.Background(), noRetry); err != nil || token != "token-1" {
+		t.Fatalf("expected cached token-1, got %q, %v", token, err)
+	}
+
+	// Callers that saw the same rejected token only cause a single fetch
+	for i := 0; i < 5; i++ {
+		tc.Invalidate("token-1")
+	}
+	source.release = make(chan struct{})
+	tokens := concurrentTokens(t, tc, source, 20)
+
+	if calls := source.calls.Load(); calls != 2 {
+		t.Fatalf("expected 2 fetches, got %d", calls)
+	}
+	for _, token := range tokens {
+		if token != "token-2" {
+			t.Fatalf("expected token-2, got %q", token)
+		}
+	}
+}
+
+func TestTokenCacheWaiterCancel(t *testing.T) {
+	source := newFakeTokenSource()
+	tc := newTokenCache(source)
+
+	go tc.Token(context.Background(), noRetry)
+	<-source.started
+
+	// A waiter gives up on its own context while the fetch is blocked
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := tc.Token(ctx, noRetry); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	close(source.release)
+}
+
+func TestTokenCacheFetcherCancel(t *testing.T) {
+	source := newFakeTokenSource()
+	tc := newTokenCache(source)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetcherDone := make(chan error)
+	go func() {
+		_, err := tc.Token(ctx, noRetry)
+		fetcherDone <- err
+	}()
+	<-source.started
+
+	waiterDone := make(chan string)
+	go func() {
+		token, err := tc.Token(context.Background(), noRetry)
+		if err != nil {
+			t.Errorf("Token() returned error: %s", err)
+		}
+		waiterDone <- token
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// The waiter starts its own fetch when the caller that started the first
+	// one gives up
+	cancel()
+	if err := <-fetcherDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	<-source.started
+	close(source.release)
+
+	if token := <-waiterDone; token != "token-2" {
+		t.Fatalf("expected token-2, got %q", token)
+	}
+}
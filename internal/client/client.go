@@ -1,130 +1,347 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
-	"net/url"
-	"strings"
 	"time"
 
-	"github.com/machinebox/graphql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Config holds the configuration for the Wiz API client
 type Config struct {
 	ClientID     string
 	ClientSecret string
-	APIURL       string
-	AuthURL      string
+	// CredentialsFile is read for the client ID and secret of Profile when they
+	// are not set
+	CredentialsFile string
+	Profile         string
+	// AccessToken is a pre-minted access token used instead of the client
+	// credentials exchange
+	AccessToken string
+	APIURL      string
+	AuthURL     string
+	// DataCenter and Environment derive the API and auth URLs when they are not
+	// set, e.g. us1 in the commercial environment
+	DataCenter  string
+	Environment string
+	// AuthProvider selects the parameters of the token request: auto, cognito
+	// or auth0. Empty means auto.
+	AuthProvider string
+	// RequestsPerSecond limits the average rate of API requests across all
+	// operations. Zero disables the limit.
+	RequestsPerSecond float64
+	// RequestBurst is the number of requests that may be sent at once
+	RequestBurst int
 }
 
 // Client is the Wiz API client
 type Client struct {
-	config        *Config
-	graphqlClient *graphql.Client
-	token         string
-	tokenExpiry   time.Time
-}
-
-type accessToken struct {
-	Token   string `json:"access_token"`
-	Expires int    `json:"expires_in"`
+	config     *Config
+	httpClient *http.Client
+	limiter    *rateLimiter
+	tokens     *tokenCache
 }
 
 // NewClient creates a new Wiz API client
 func NewClient(config *Config) (*Client, error) {
-	if config.ClientID == "" || config.ClientSecret == "" {
-		return nil, fmt.Errorf("client_id and client_secret are required")
+	if err := resolveURLs(config); err != nil {
+		return nil, err
 	}
 
-	if config.APIURL == "" {
-		config.APIURL = "https://api.eu1.demo.wiz.io/graphql"
+	authProvider, err := resolveAuthProvider(config.AuthProvider, config.AuthURL)
+	if err != nil {
+		return nil, err
 	}
 
-	if config.AuthURL == "" {
-		config.AuthURL = "https://auth.demo.wiz.io/oauth/token"
-	}
+	httpClient := &http.Client{}
 
-	graphqlClient := graphql.NewClient(config.APIURL)
+	source, err := newTokenSource(config, httpClient, authAudiences[authProvider])
+	if err != nil {
+		return nil, err
+	}
 
 	return &Client{
-		config:        config,
-		graphqlClient: graphqlClient,
+		config:     config,
+		httpClient: httpClient,
+		limiter:    newRateLimiter(config.RequestsPerSecond, config.RequestBurst),
+		tokens:     newTokenCache(source),
 	}, nil
 }
 
-// authenticate gets a new access token
-func (c *Client) authenticate() error {
-	// Check if token is still valid
-	if c.token != "" && time.Now().Before(c.tokenExpiry) {
-		return nil
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphQLResponse is the body of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors"`
+}
+
+// graphQLError is a single entry of the errors array of a GraphQL response
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// RunQuery executes a GraphQL query. Errors returned by the API are *APIError
+// values that can be checked with errors.Is against the Err* kinds.
+func (c *Client) RunQuery(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
+	ctx = withLogging(ctx)
+
+	token, err := c.authenticate(ctx)
+	if err != nil {
+		return err
 	}
 
-	authData := url.Values{}
-	authData.Set("grant_type", "client_credentials")
-	authData.Set("audience", "wiz-api")
-	authData.Set("client_id", c.config.ClientID)
-	authData.Set("client_secret", c.config.ClientSecret)
+	err = c.runQuery(ctx, token, query, variables, response)
+	if isUnauthenticated(err) {
+		// The token was rejected, e.g. because it expired early or was revoked,
+		// so get a new one and try once more
+		tflog.SubsystemInfo(ctx, logSubsystemAuth, "Access token was rejected, authenticating again", map[string]interface{}{
+			"operation": graphQLOperationName(query),
+		})
+		c.tokens.Invalidate(token)
+		if token, err = c.authenticate(ctx); err != nil {
+			return err
+		}
+		err = c.runQuery(ctx, token, query, variables, response)
+	}
 
-	httpClient := &http.Client{}
+	return err
+}
+
+// runQuery sends a single GraphQL request with the given access token
+func (c *Client) runQuery(ctx context.Context, token string, query string, variables map[string]interface{}, response interface{}) error {
+	body, err := json.Marshal(graphQLRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding request: %w", err)
+	}
 
-	req, err := http.NewRequest(http.MethodPost, c.config.AuthURL, strings.NewReader(authData.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.APIURL, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("error creating authentication request: %w", err)
+		return fmt.Errorf("error creating request: %w", err)
 	}
 
-	req.Header.Add("Encoding", "UTF-8")
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := httpClient.Do(req)
+	// Wait for the shared rate limiter before sending the request
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	operation := graphQLOperationName(query)
+	tflog.SubsystemDebug(ctx, logSubsystemClient, "Sending GraphQL request", map[string]interface{}{
+		"operation": operation,
+		"variables": redactVariables(variables),
+	})
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error authenticating: %w", err)
+		err = newTransportError(err)
+		tflog.SubsystemDebug(ctx, logSubsystemClient, "GraphQL request failed", map[string]interface{}{
+			"operation":  operation,
+			"latency_ms": time.Since(start).Milliseconds(),
+			"error":      err.Error(),
+		})
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("error authenticating, status code: %d", resp.StatusCode)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return newTransportError(err)
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("error reading authentication response: %w", err)
+	tflog.SubsystemDebug(ctx, logSubsystemClient, "Received GraphQL response", map[string]interface{}{
+		"operation":   operation,
+		"status_code": resp.StatusCode,
+		"latency_ms":  time.Since(start).Milliseconds(),
+	})
+
+	var gr graphQLResponse
+	if err := json.Unmarshal(respBody, &gr); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("error decoding response: %w", err)
 	}
 
-	var at accessToken
-	if err := json.Unmarshal(bodyBytes, &at); err != nil {
-		return fmt.Errorf("error parsing authentication response: %w", err)
+	if len(gr.Errors) > 0 || resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			Kind:       kindFromStatusCode(resp.StatusCode),
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+
+		if len(gr.Errors) > 0 {
+			// Classify by the first error, falling back to the HTTP status code
+			first := gr.Errors[0]
+			apiErr.Code = first.Extensions.Code
+			apiErr.Message = first.Message
+			if kind := kindFromGraphQLCode(first.Extensions.Code); kind != nil {
+				apiErr.Kind = kind
+			}
+		}
+
+		// A rate limit response pauses every request made through this client
+		if apiErr.Kind == ErrRateLimited {
+			cooldown := apiErr.RetryAfter
+			if cooldown <= 0 {
+				cooldown = defaultRateLimitCooldown
+			}
+			tflog.SubsystemWarn(ctx, logSubsystemClient, "Rate limited by the API, pausing all requests", map[string]interface{}{
+				"operation": operation,
+				"cooldown":  cooldown.String(),
+			})
+			c.limiter.Pause(cooldown)
+		}
+
+		tflog.SubsystemDebug(ctx, logSubsystemClient, "GraphQL request returned an error", map[string]interface{}{
+			"operation": operation,
+			"error":     apiErr.Error(),
+		})
+
+		return apiErr
 	}
 
-	c.token = at.Token
-	c.tokenExpiry = time.Now().Add(time.Duration(at.Expires) * time.Second)
+	if response != nil && len(gr.Data) > 0 {
+		if err := json.Unmarshal(gr.Data, response); err != nil {
+			return fmt.Errorf("error decoding response data: %w", err)
+		}
+	}
 
 	return nil
 }
 
-// RunQuery executes a GraphQL query
-func (c *Client) RunQuery(ctx context.Context, query string, variables map[string]interface{}, response interface{}) error {
-	if err := c.authenticate(); err != nil {
-		return err
-	}
+// PageInfo is the pagination state of a GraphQL connection
+type PageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// listPages runs a paginated query, following the pagination cursors until all
+// pages have been read. The query takes $first, $after and $filterBy variables,
+// and page returns the nodes and page info of a response.
+func listPages[R any, T any](ctx context.Context, c *Client, query string, filterBy map[string]interface{}, pageSize int, page func(*R) ([]T, PageInfo)) ([]T, error) {
+	var nodes []T
+	cursor := ""
+	for {
+		variables := map[string]interface{}{
+			"first":    pageSize,
+			"filterBy": filterBy,
+		}
+		if cursor != "" {
+			variables["after"] = cursor
+		}
+
+		var response R
+		err := c.retryWithBackoff(ctx, func() error {
+			return c.RunQuery(ctx, query, variables, &response)
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	req := graphql.NewRequest(query)
+		pageNodes, pageInfo := page(&response)
+		nodes = append(nodes, pageNodes...)
 
-	// Set variables
-	for k, v := range variables {
-		req.Var(k, v)
+		if !pageInfo.HasNextPage || pageInfo.EndCursor == "" {
+			return nodes, nil
+		}
+		cursor = pageInfo.EndCursor
 	}
+}
 
-	// Set auth header
-	req.Header.Set("Authorization", "Bearer "+c.token)
+// retryWithBackoff retries a function with exponential backoff. When the API
+// asks for a longer delay through Retry-After, that delay is used instead.
+func (c *Client) retryWithBackoff(ctx context.Context, f func() error) error {
+	return c.retryWithBackoffIf(ctx, isRetryableError, f)
+}
 
-	// Run the query
-	if err := c.graphqlClient.Run(ctx, req, response); err != nil {
-		return err
+// retryUnsent retries a function with backoff only when the API did not process
+// the request, so it is safe for mutations that must not be applied twice
+func (c *Client) retryUnsent(ctx context.Context, f func() error) error {
+	return c.retryWithBackoffIf(ctx, isUnprocessedError, f)
+}
+
+// retryWithBackoffIf retries a function with backoff for as long as it fails
+// with errors accepted by retryable
+func (c *Client) retryWithBackoffIf(ctx context.Context, retryable func(error) bool, f func() error) error {
+	ctx = withLogging(ctx)
+
+	var err error
+	maxRetries := 5
+	baseDelay := 1 * time.Second
+
+	for i := 0; i < maxRetries; i++ {
+		err = f()
+		if err == nil {
+			return nil
+		}
+
+		// Check if error is retryable
+		if !retryable(err) {
+			return err
+		}
+
+		// Calculate backoff with jitter
+		delay := baseDelay * time.Duration(1<<uint(i)) // Exponential
+		jitter := time.Duration(rand.Int63n(int64(delay) / 2))
+		delay = delay + jitter
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			delay = apiErr.RetryAfter
+		}
+
+		tflog.SubsystemWarn(ctx, logSubsystemClient, "Retrying request", map[string]interface{}{
+			"attempt":     i + 1,
+			"max_retries": maxRetries,
+			"delay":       delay.String(),
+			"error":       err.Error(),
+		})
+
+		select {
+		case <-time.After(delay):
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
-	return nil
+	return fmt.Errorf("maximum retries exceeded: %w", err)
+}
+
+// isRetryableError determines if an error is retryable
+func isRetryableError(err error) bool {
+	// Network errors, rate limits, and temporary API issues can be retried
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTransient)
+}
+
+// isUnprocessedError reports whether an error means the request was not
+// processed by the API: it was rate limited, or the connection could not be
+// established. Other transient errors may happen after the API applied a
+// mutation.
+func isUnprocessedError(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
 }
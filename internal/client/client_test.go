@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testAPI is a fake Wiz API serving the token endpoint and the GraphQL endpoint
+type testAPI struct {
+	tokenRequests   atomic.Int32
+	graphQLRequests atomic.Int32
+	// token handles token requests after the default response, if set
+	token func(w http.ResponseWriter, r *http.Request, n int32) bool
+	// graphQL handles GraphQL requests with the number of the request
+	graphQL func(w http.ResponseWriter, r *http.Request, n int32)
+}
+
+func newTestClient(t *testing.T, api *testAPI) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		n := api.tokenRequests.Add(1)
+		if api.token != nil && api.token(w, r, n) {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(accessToken{
+			Token:   fmt.Sprintf("token-%d", n),
+			Expires: 3600,
+		})
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		api.graphQL(w, r, api.graphQLRequests.Add(1))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(&Config{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		APIURL:       server.URL + "/graphql",
+		AuthURL:      server.URL + "/oauth/token",
+	})
+	if err != nil {
+		t.Fatalf("NewClient() returned error: %s", err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
+
+func TestRunQueryReauthenticatesOnce(t *testing.T) {
+	api := &testAPI{
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				writeJSON(w, http.StatusUnauthorized, `{"errors":[{"message":"token expired","extensions":{"code":"UNAUTHENTICATED"}}]}`)
+				return
+			}
+			writeJSON(w, http.StatusOK, `{"data":{"ok":true}}`)
+		},
+	}
+	c := newTestClient(t, api)
+
+	var response struct {
+		OK bool `json:"ok"`
+	}
+	if err := c.RunQuery(context.Background(), "query Test { ok }", nil, &response); err != nil {
+		t.Fatalf("RunQuery() returned error: %s", err)
+	}
+	if !response.OK {
+		t.Errorf("expected the response to be decoded")
+	}
+	if n := api.tokenRequests.Load(); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+	if n := api.graphQLRequests.Load(); n != 2 {
+		t.Errorf("expected 2 GraphQL requests, got %d", n)
+	}
+}
+
+func TestRunQueryUnauthorizedTwice(t *testing.T) {
+	api := &testAPI{
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			writeJSON(w, http.StatusUnauthorized, `{"errors":[{"message":"forbidden","extensions":{"code":"UNAUTHENTICATED"}}]}`)
+		},
+	}
+	c := newTestClient(t, api)
+
+	err := c.RunQuery(context.Background(), "query Test { ok }", nil, nil)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if n := api.graphQLRequests.Load(); n != 2 {
+		t.Errorf("expected 2 GraphQL requests, got %d", n)
+	}
+}
+
+func TestRunQueryRateLimited(t *testing.T) {
+	api := &testAPI{
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			w.Header().Set("Retry-After", "30")
+			writeJSON(w, http.StatusTooManyRequests, `{"errors":[{"message":"slow down"}]}`)
+		},
+	}
+	c := newTestClient(t, api)
+
+	err := c.RunQuery(context.Background(), "query Test { ok }", nil, nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 30*time.Second {
+		t.Errorf("expected a Retry-After of 30s, got %v", apiErr)
+	}
+
+	// Every request made through the client waits for the cool-down
+	if delay := c.limiter.reserve(); delay <= 29*time.Second {
+		t.Errorf("expected the limiter to be paused for 30s, got %s", delay)
+	}
+}
+
+func TestRunQueryNotFound(t *testing.T) {
+	api := &testAPI{
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			writeJSON(w, http.StatusOK, `{"data":{"connector":null},"errors":[{"message":"Connector was deleted","extensions":{"code":"NOT_FOUND"}}]}`)
+		},
+	}
+	c := newTestClient(t, api)
+
+	err := c.RunQuery(context.Background(), "query GetConnector { connector { id } }", nil, nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "NOT_FOUND" || apiErr.Message != "Connector was deleted" {
+		t.Errorf("expected the GraphQL error details, got %v", apiErr)
+	}
+}
+
+func TestGetConnectorNull(t *testing.T) {
+	api := &testAPI{
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			writeJSON(w, http.StatusOK, `{"data":{"connector":null}}`)
+		},
+	}
+	c := newTestClient(t, api)
+
+	if _, err := c.GetConnector(context.Background(), "id"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRunQueryBadGateway(t *testing.T) {
+	api := &testAPI{
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>Bad Gateway</html>"))
+		},
+	}
+	c := newTestClient(t, api)
+
+	err := c.RunQuery(context.Background(), "query Test { ok }", nil, nil)
+	if !errors.Is(err, ErrTransient) {
+		t.Fatalf("expected ErrTransient, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %v", apiErr)
+	}
+}
+
+func TestRunQueryRetriesFailedAuthentication(t *testing.T) {
+	api := &testAPI{
+		token: func(w http.ResponseWriter, r *http.Request, n int32) bool {
+			if n == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return true
+			}
+			return false
+		},
+		graphQL: func(w http.ResponseWriter, r *http.Request, n int32) {
+			writeJSON(w, http.StatusOK, `{"data":{"ok":true}}`)
+		},
+	}
+	c := newTestClient(t, api)
+
+	if err := c.RunQuery(context.Background(), "query Test { ok }", nil, nil); err != nil {
+		t.Fatalf("RunQuery() returned error: %s", err)
+	}
+	if n := api.tokenRequests.Load(); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+}
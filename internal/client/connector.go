@@ -3,37 +3,68 @@ package client
 import (
 	"context"
 	"fmt"
-	"math/rand"
-	"strings"
-	"time"
 )
 
+// ConnectorConfigCheck represents a single check run by testConnectorConfig
+type ConnectorConfigCheck struct {
+	Name        string `json:"name"`
+	Success     bool   `json:"success"`
+	Error       string `json:"error"`
+	Remediation string `json:"remediation"`
+}
+
+// TestConnectorConfigResult represents the result of testing a connector configuration
+type TestConnectorConfigResult struct {
+	Success bool `json:"success"`
+	// Error is the reason the test failed, if any
+	Error string `json:"error"`
+	// Remediation is a hint on how to fix the failure, if any
+	Remediation string                 `json:"remediation"`
+	Checks      []ConnectorConfigCheck `json:"checks"`
+}
+
+// Errors returns the failure reason followed by the errors of the failed checks
+func (r *TestConnectorConfigResult) Errors() []string {
+	errs := []string{}
+	if r.Error != "" {
+		errs = append(errs, r.Error)
+	}
+	for _, check := range r.Checks {
+		if !check.Success && check.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", check.Name, check.Error))
+		}
+	}
+	return errs
+}
+
 // TestConnectorConfigResponse represents the response from the testConnectorConfig mutation
 type TestConnectorConfigResponse struct {
-	TestConnectorConfig struct {
-		Success bool `json:"success"`
-	} `json:"testConnectorConfig"`
+	TestConnectorConfig TestConnectorConfigResult `json:"testConnectorConfig"`
+}
+
+// CreatedConnector represents the connector returned by the createConnector mutation.
+// The outpost service account credentials are only returned by this mutation.
+type CreatedConnector struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	AuthParams interface{} `json:"authParams"`
+	Type       struct {
+		ID string `json:"id"`
+	} `json:"type"`
+	ExtraConfig interface{} `json:"extraConfig"`
+	Outpost     struct {
+		ID             string `json:"id"`
+		ServiceAccount struct {
+			ClientID     string `json:"clientId"`
+			ClientSecret string `json:"clientSecret"`
+		} `json:"serviceAccount"`
+	} `json:"outpost"`
 }
 
 // CreateConnectorResponse represents the response from the createConnector mutation
 type CreateConnectorResponse struct {
 	CreateConnector struct {
-		Connector struct {
-			ID         string      `json:"id"`
-			Name       string      `json:"name"`
-			AuthParams interface{} `json:"authParams"`
-			Type       struct {
-				ID string `json:"id"`
-			} `json:"type"`
-			ExtraConfig interface{} `json:"extraConfig"`
-			Outpost     struct {
-				ID             string `json:"id"`
-				ServiceAccount struct {
-					ClientID     string `json:"clientId"`
-					ClientSecret string `json:"clientSecret"`
-				} `json:"serviceAccount"`
-			} `json:"outpost"`
-		} `json:"connector"`
+		Connector CreatedConnector `json:"connector"`
 	} `json:"createConnector"`
 }
 
@@ -45,7 +76,7 @@ type DeleteConnectorResponse struct {
 }
 
 // TestConnectorConfig tests a connector configuration
-func (c *Client) TestConnectorConfig(ctx context.Context, connectorType string, authParams map[string]interface{}, extraConfig map[string]interface{}, id string) (bool, error) {
+func (c *Client) TestConnectorConfig(ctx context.Context, connectorType string, authParams map[string]interface{}, extraConfig map[string]interface{}, id string) (*TestConnectorConfigResult, error) {
 	query := `
 		query TestConnectorConfig($type: ID!, $authParams: JSON!, $extraConfig: JSON, $id: String) {
 			testConnectorConfig(
@@ -55,6 +86,14 @@ func (c *Client) TestConnectorConfig(ctx context.Context, connectorType string,
 				id: $id
 			) {
 				success
+				error
+				remediation
+				checks {
+					name
+					success
+					error
+					remediation
+				}
 			}
 		}
 	`
@@ -73,15 +112,39 @@ func (c *Client) TestConnectorConfig(ctx context.Context, connectorType string,
 	}
 
 	var response TestConnectorConfigResponse
-	if err := c.RunQuery(ctx, query, variables, &response); err != nil {
-		return false, fmt.Errorf("error testing connector config: %w", err)
+	err := c.retryUnsent(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error testing connector config: %w", err)
 	}
 
-	return response.TestConnectorConfig.Success, nil
+	return &response.TestConnectorConfig, nil
+}
+
+// CreateConnectorInput holds the fields used to create a connector
+type CreateConnectorInput struct {
+	Name        string
+	Type        string
+	AuthParams  map[string]interface{}
+	ExtraConfig map[string]interface{}
+	Enabled     bool
+	// OutpostID is the outpost the connector runs through. The default outpost
+	// is used if it is empty.
+	OutpostID string
+}
+
+// UpdateConnectorPatch holds the fields to change on a connector. Empty and nil
+// fields are left out of the patch and keep their current value.
+type UpdateConnectorPatch struct {
+	Name        string
+	AuthParams  map[string]interface{}
+	ExtraConfig map[string]interface{}
+	Enabled     *bool
 }
 
 // CreateConnector creates a new connector
-func (c *Client) CreateConnector(ctx context.Context, name string, connectorType string, authParams map[string]interface{}, extraConfig map[string]interface{}) (string, error) {
+func (c *Client) CreateConnector(ctx context.Context, input CreateConnectorInput) (*CreatedConnector, error) {
 	query := `
 		mutation CreateConnector($input: CreateConnectorInput!) {
 			createConnector(input: $input) {
@@ -105,26 +168,34 @@ func (c *Client) CreateConnector(ctx context.Context, name string, connectorType
 		}
 	`
 
-	input := map[string]interface{}{
-		"name":       name,
-		"type":       connectorType,
-		"authParams": authParams,
+	connectorInput := map[string]interface{}{
+		"name":       input.Name,
+		"type":       input.Type,
+		"authParams": input.AuthParams,
+		"enabled":    input.Enabled,
 	}
 
-	if extraConfig != nil {
-		input["extraConfig"] = extraConfig
+	if input.ExtraConfig != nil {
+		connectorInput["extraConfig"] = input.ExtraConfig
+	}
+
+	if input.OutpostID != "" {
+		connectorInput["outpostId"] = input.OutpostID
 	}
 
 	variables := map[string]interface{}{
-		"input": input,
+		"input": connectorInput,
 	}
 
 	var response CreateConnectorResponse
-	if err := c.RunQuery(ctx, query, variables, &response); err != nil {
-		return "", fmt.Errorf("error creating connector: %w", err)
+	err := c.retryUnsent(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating connector: %w", err)
 	}
 
-	return response.CreateConnector.Connector.ID, nil
+	return &response.CreateConnector.Connector, nil
 }
 
 // DeleteConnector deletes a connector
@@ -144,7 +215,10 @@ func (c *Client) DeleteConnector(ctx context.Context, id string) error {
 	}
 
 	var response DeleteConnectorResponse
-	if err := c.RunQuery(ctx, query, variables, &response); err != nil {
+	err := c.retryUnsent(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
 		return fmt.Errorf("error deleting connector: %w", err)
 	}
 
@@ -300,7 +374,7 @@ func (c *Client) GetConnector(ctx context.Context, id string) (map[string]interf
 	}
 
 	var response map[string]interface{}
-	err := retryWithBackoff(ctx, func() error {
+	err := c.retryWithBackoff(ctx, func() error {
 		return c.RunQuery(ctx, query, variables, &response)
 	})
 
@@ -311,7 +385,10 @@ func (c *Client) GetConnector(ctx context.Context, id string) (map[string]interf
 	// Check if connector exists
 	connectorData, ok := response["connector"]
 	if !ok || connectorData == nil {
-		return nil, fmt.Errorf("connector not found: %s", id)
+		return nil, &APIError{
+			Kind:    ErrNotFound,
+			Message: fmt.Sprintf("connector not found: %s", id),
+		}
 	}
 
 	// Convert to map
@@ -324,7 +401,7 @@ func (c *Client) GetConnector(ctx context.Context, id string) (map[string]interf
 }
 
 // UpdateConnector updates an existing connector
-func (c *Client) UpdateConnector(ctx context.Context, id string, name string, authParams map[string]interface{}, extraConfig map[string]interface{}) error {
+func (c *Client) UpdateConnector(ctx context.Context, id string, connectorPatch UpdateConnectorPatch) error {
 	query := `
 		mutation UpdateConnector($input: UpdateConnectorInput!) {
 		  updateConnector(input: $input) {
@@ -341,16 +418,22 @@ func (c *Client) UpdateConnector(ctx context.Context, id string, name string, au
 	`
 
 	// Build the patch object with the changes
-	patch := map[string]interface{}{
-		"name": name,
+	patch := map[string]interface{}{}
+
+	if connectorPatch.Name != "" {
+		patch["name"] = connectorPatch.Name
 	}
 
-	if authParams != nil {
-		patch["authParams"] = authParams
+	if connectorPatch.AuthParams != nil {
+		patch["authParams"] = connectorPatch.AuthParams
 	}
 
-	if extraConfig != nil {
-		patch["extraConfig"] = extraConfig
+	if connectorPatch.ExtraConfig != nil {
+		patch["extraConfig"] = connectorPatch.ExtraConfig
+	}
+
+	if connectorPatch.Enabled != nil {
+		patch["enabled"] = *connectorPatch.Enabled
 	}
 
 	variables := map[string]interface{}{
@@ -361,7 +444,7 @@ func (c *Client) UpdateConnector(ctx context.Context, id string, name string, au
 	}
 
 	var response UpdateConnectorResponse
-	err := retryWithBackoff(ctx, func() error {
+	err := c.retryWithBackoff(ctx, func() error {
 		return c.RunQuery(ctx, query, variables, &response)
 	})
 
@@ -372,56 +455,127 @@ func (c *Client) UpdateConnector(ctx context.Context, id string, name string, au
 	return nil
 }
 
-// retryWithBackoff retries a function with exponential backoff
-func retryWithBackoff(ctx context.Context, f func() error) error {
-	var err error
-	maxRetries := 5
-	baseDelay := 1 * time.Second
+// ConnectorFilter narrows down the connectors returned by ListConnectors.
+// Empty fields are not filtered on.
+type ConnectorFilter struct {
+	// Name matches the connector name exactly
+	Name string
+	// Type matches the connector type ID, e.g. aws
+	Type string
+	// Status matches the connector status, e.g. CONNECTED
+	Status string
+	// Enabled matches whether the connector is enabled
+	Enabled *bool
+	// OutpostID matches the ID of the outpost the connector runs through
+	OutpostID string
+}
 
-	for i := 0; i < maxRetries; i++ {
-		err = f()
-		if err == nil {
-			return nil
-		}
+// ConnectorSummary represents a connector returned by the connectors query
+type ConnectorSummary struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	Enabled      bool   `json:"enabled"`
+	LastActivity string `json:"lastActivity"`
+	Type         struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"type"`
+	Outpost *struct {
+		ID string `json:"id"`
+	} `json:"outpost"`
+}
 
-		// Check if error is retryable
-		if !isRetryableError(err) {
-			return err
+// ListConnectorsResponse represents the response from the connectors query
+type ListConnectorsResponse struct {
+	Connectors struct {
+		Nodes    []ConnectorSummary `json:"nodes"`
+		PageInfo PageInfo           `json:"pageInfo"`
+	} `json:"connectors"`
+}
+
+// listConnectorsPageSize is the number of connectors requested per page
+const listConnectorsPageSize = 100
+
+// ListConnectors lists the connectors matching the filter, following the
+// pagination cursors until all pages have been read
+func (c *Client) ListConnectors(ctx context.Context, filter ConnectorFilter) ([]ConnectorSummary, error) {
+	query := `
+		query ListConnectors($first: Int, $after: String, $filterBy: ConnectorFilters) {
+		  connectors(first: $first, after: $after, filterBy: $filterBy) {
+			nodes {
+			  id
+			  name
+			  status
+			  enabled
+			  lastActivity
+			  type {
+				id
+				name
+			  }
+			  outpost {
+				id
+			  }
+			}
+			pageInfo {
+			  hasNextPage
+			  endCursor
+			}
+		  }
 		}
+	`
+
+	// Narrow the query down on the server, exact matches are checked below
+	filterBy := map[string]interface{}{}
+	if filter.Name != "" {
+		filterBy["search"] = filter.Name
+	}
+	if filter.Type != "" {
+		filterBy["type"] = []string{filter.Type}
+	}
+	if filter.Status != "" {
+		filterBy["status"] = []string{filter.Status}
+	}
+	if filter.Enabled != nil {
+		filterBy["enabled"] = *filter.Enabled
+	}
+	if filter.OutpostID != "" {
+		filterBy["outpost"] = []string{filter.OutpostID}
+	}
 
-		// Calculate backoff with jitter
-		delay := baseDelay * time.Duration(1<<uint(i)) // Exponential
-		jitter := time.Duration(rand.Int63n(int64(delay) / 2))
-		delay = delay + jitter
+	nodes, err := listPages(ctx, c, query, filterBy, listConnectorsPageSize, func(r *ListConnectorsResponse) ([]ConnectorSummary, PageInfo) {
+		return r.Connectors.Nodes, r.Connectors.PageInfo
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing connectors: %w", err)
+	}
 
-		select {
-		case <-time.After(delay):
-			continue
-		case <-ctx.Done():
-			return ctx.Err()
+	var connectors []ConnectorSummary
+	for _, connector := range nodes {
+		if filter.matches(connector) {
+			connectors = append(connectors, connector)
 		}
 	}
 
-	return fmt.Errorf("maximum retries exceeded: %w", err)
+	return connectors, nil
 }
 
-// isRetryableError determines if an error is retryable
-func isRetryableError(err error) bool {
-	// Check for network errors, rate limits, and temporary API issues
-	if strings.Contains(err.Error(), "rate limit") {
-		return true
+// matches reports whether a connector matches every field set on the filter
+func (f ConnectorFilter) matches(connector ConnectorSummary) bool {
+	if f.Name != "" && connector.Name != f.Name {
+		return false
 	}
-	if strings.Contains(err.Error(), "timeout") {
-		return true
+	if f.Type != "" && connector.Type.ID != f.Type {
+		return false
 	}
-	if strings.Contains(err.Error(), "connection reset") {
-		return true
+	if f.Status != "" && connector.Status != f.Status {
+		return false
 	}
-	if strings.Contains(err.Error(), "temporary") {
-		return true
+	if f.Enabled != nil && connector.Enabled != *f.Enabled {
+		return false
 	}
-	if strings.Contains(err.Error(), "service unavailable") {
-		return true
+	if f.OutpostID != "" && (connector.Outpost == nil || connector.Outpost.ID != f.OutpostID) {
+		return false
 	}
-	return false
+	return true
 }
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultProfile is the credentials file profile used when none is configured
+const DefaultProfile = "default"
+
+// staticTokenSource returns a pre-minted access token, e.g. one issued by a
+// secrets broker. The token cannot be refreshed; once it expires the API rejects
+// it and requests fail.
+type staticTokenSource struct {
+	token string
+}
+
+// Token returns the static token. A zero lifetime makes the cache ask for it on
+// every use, which costs nothing.
+func (s *staticTokenSource) Token(ctx context.Context) (string, time.Duration, error) {
+	return s.token, 0, nil
+}
+
+// serviceAccountCredentials are the credentials of a profile in a credentials file
+type serviceAccountCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// loadCredentialsFile reads the service account credentials of a profile from a
+// credentials file. The file is either a JSON object keyed by profile name, or an
+// INI file with a section per profile:
+//
+//	[default]
+//	client_id = ...
+//	client_secret = ...
+func loadCredentialsFile(path string, profile string) (*serviceAccountCredentials, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("error expanding credentials file path: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials file: %w", err)
+	}
+
+	var profiles map[string]serviceAccountCredentials
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &profiles); err != nil {
+			return nil, fmt.Errorf("error parsing credentials file %s: %w", path, err)
+		}
+	} else {
+		profiles, err = parseCredentialsINI(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing credentials file %s: %w", path, err)
+		}
+	}
+
+	credentials, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in credentials file %s", profile, path)
+	}
+	if credentials.ClientID == "" || credentials.ClientSecret == "" {
+		return nil, fmt.Errorf("profile %q in credentials file %s must set client_id and client_secret", profile, path)
+	}
+
+	return &credentials, nil
+}
+
+// parseCredentialsINI parses the profiles of an INI credentials file. Lines
+// starting with # or ; are comments.
+func parseCredentialsINI(data []byte) (map[string]serviceAccountCredentials, error) {
+	profiles := map[string]serviceAccountCredentials{}
+	profile := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			profile = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[profile]; ok {
+				return nil, fmt.Errorf("line %d: duplicate profile %q", lineNumber, profile)
+			}
+			profiles[profile] = serviceAccountCredentials{}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		if profile == "" {
+			return nil, fmt.Errorf("line %d: %s is not in a [profile] section", lineNumber, strings.TrimSpace(key))
+		}
+
+		credentials := profiles[profile]
+		switch strings.TrimSpace(key) {
+		case "client_id":
+			credentials.ClientID = strings.TrimSpace(value)
+		case "client_secret":
+			credentials.ClientSecret = strings.TrimSpace(value)
+		}
+		profiles[profile] = credentials
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// newTokenSource returns the token source for the credentials in the config: a
+// static access token, the client ID and secret, or a credentials file profile,
+// in this order of priority
+func newTokenSource(config *Config, httpClient *http.Client, audience string) (tokenSource, error) {
+	if config.AccessToken != "" {
+		return &staticTokenSource{token: config.AccessToken}, nil
+	}
+
+	clientID, clientSecret := config.ClientID, config.ClientSecret
+	if (clientID == "") != (clientSecret == "") {
+		return nil, fmt.Errorf("client_id and client_secret must be set together")
+	}
+	if clientID == "" && config.CredentialsFile != "" {
+		credentials, err := loadCredentialsFile(config.CredentialsFile, config.Profile)
+		if err != nil {
+			return nil, err
+		}
+		clientID, clientSecret = credentials.ClientID, credentials.ClientSecret
+	}
+
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("no credentials configured: set access_token, client_id and client_secret, or credentials_file")
+	}
+
+	return &clientCredentialsSource{
+		httpClient:   httpClient,
+		authURL:      config.AuthURL,
+		audience:     audience,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}, nil
+}
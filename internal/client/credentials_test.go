@@ -0,0 +1,76 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCredentialsINI(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected map[string]serviceAccountCredentials
+		err      string
+	}{
+		{
+			name: "profiles",
+			data: `
+# Wiz service accounts
+[default]
+client_id = id
+client_secret = secret
+
+; another tenant
+[ staging ]
+client_id=staging-id
+client_secret=staging-secret
+unknown = ignored
+`,
+			expected: map[string]serviceAccountCredentials{
+				"default": {ClientID: "id", ClientSecret: "secret"},
+				"staging": {ClientID: "staging-id", ClientSecret: "staging-secret"},
+			},
+		},
+		{
+			name: "empty profile",
+			data: "[default]\n",
+			expected: map[string]serviceAccountCredentials{
+				"default": {},
+			},
+		},
+		{
+			name: "duplicate profile",
+			data: "[default]\nclient_id = id\n[default]\nclient_secret = secret\n",
+			err:  `line 3: duplicate profile "default"`,
+		},
+		{
+			name: "key outside a profile",
+			data: "client_id = id\n[default]\n",
+			err:  "line 1: client_id is not in a [profile] section",
+		},
+		{
+			name: "line without value",
+			data: "[default]\nclient_id\n",
+			err:  "line 2: expected key = value",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			profiles, err := parseCredentialsINI([]byte(tc.data))
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(profiles, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, profiles)
+			}
+		})
+	}
+}
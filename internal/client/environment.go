@@ -0,0 +1,117 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvironment is the environment used when none is configured
+const DefaultEnvironment = "commercial"
+
+// environment describes the hosts of a Wiz environment
+type environment struct {
+	// apiDomain is the domain of the API hosts, which are named api.<data center>.<domain>
+	apiDomain string
+	// authURL is the token endpoint of the environment
+	authURL string
+	// authHosts are the hosts of every auth endpoint of the environment
+	authHosts []string
+}
+
+// environments holds the Wiz environments by name
+var environments = map[string]environment{
+	"commercial": {
+		apiDomain: "app.wiz.io",
+		authURL:   "https://auth.app.wiz.io/oauth/token",
+		authHosts: []string{"auth.app.wiz.io", "auth.wiz.io"},
+	},
+	"gov": {
+		apiDomain: "gov.wiz.io",
+		authURL:   "https://auth.gov.wiz.io/oauth/token",
+		authHosts: []string{"auth.gov.wiz.io"},
+	},
+	"fedramp": {
+		apiDomain: "app.wiz.us",
+		authURL:   "https://auth.app.wiz.us/oauth/token",
+		authHosts: []string{"auth.app.wiz.us"},
+	},
+	"demo": {
+		apiDomain: "demo.wiz.io",
+		authURL:   "https://auth.demo.wiz.io/oauth/token",
+		authHosts: []string{"auth.demo.wiz.io"},
+	},
+}
+
+// Environments returns the names of the supported environments in sorted order
+func Environments() []string {
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveURLs fills in the API and auth URLs of the config from its data center
+// and environment. URLs set explicitly take priority. It fails if a URL cannot be
+// derived, or if the API and auth hosts belong to different environments.
+func resolveURLs(config *Config) error {
+	// Without an explicit environment, an explicit api_url decides the environment
+	envName := config.Environment
+	if envName == "" && config.APIURL != "" {
+		envName = hostEnvironment(config.APIURL, false)
+	}
+	if envName == "" {
+		envName = DefaultEnvironment
+	}
+	env, ok := environments[envName]
+	if !ok {
+		return fmt.Errorf("unknown environment %q, expected one of %s", envName, strings.Join(Environments(), ", "))
+	}
+
+	if config.APIURL == "" {
+		if config.DataCenter == "" {
+			return fmt.Errorf("either api_url or data_center is required, e.g. data_center = \"us1\"")
+		}
+		config.APIURL = fmt.Sprintf("https://api.%s.%s/graphql", config.DataCenter, env.apiDomain)
+	}
+
+	if config.AuthURL == "" {
+		config.AuthURL = env.authURL
+	}
+
+	apiEnv := hostEnvironment(config.APIURL, false)
+	authEnv := hostEnvironment(config.AuthURL, true)
+	if apiEnv != "" && authEnv != "" && apiEnv != authEnv {
+		return fmt.Errorf("api_url %s belongs to the %s environment but auth_url %s belongs to the %s environment",
+			config.APIURL, apiEnv, config.AuthURL, authEnv)
+	}
+
+	return nil
+}
+
+// hostEnvironment returns the name of the environment the host of rawURL belongs
+// to, or an empty string if it is not a known Wiz host
+func hostEnvironment(rawURL string, auth bool) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := u.Hostname()
+
+	for name, env := range environments {
+		if auth {
+			for _, authHost := range env.authHosts {
+				if host == authHost {
+					return name
+				}
+			}
+		} else if strings.HasPrefix(host, "api.") && strings.HasSuffix(host, "."+env.apiDomain) {
+			return name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveURLs(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  Config
+		apiURL  string
+		authURL string
+		err     string
+	}{
+		{
+			name:    "data center",
+			config:  Config{DataCenter: "us1"},
+			apiURL:  "https://api.us1.app.wiz.io/graphql",
+			authURL: "https://auth.app.wiz.io/oauth/token",
+		},
+		{
+			name:    "data center in environment",
+			config:  Config{DataCenter: "us1", Environment: "fedramp"},
+			apiURL:  "https://api.us1.app.wiz.us/graphql",
+			authURL: "https://auth.app.wiz.us/oauth/token",
+		},
+		{
+			name:    "environment from api_url",
+			config:  Config{APIURL: "https://api.demo.demo.wiz.io/graphql"},
+			apiURL:  "https://api.demo.demo.wiz.io/graphql",
+			authURL: "https://auth.demo.wiz.io/oauth/token",
+		},
+		{
+			name:    "explicit urls",
+			config:  Config{APIURL: "https://api.us1.app.wiz.io/graphql", AuthURL: "https://auth.wiz.io/oauth/token"},
+			apiURL:  "https://api.us1.app.wiz.io/graphql",
+			authURL: "https://auth.wiz.io/oauth/token",
+		},
+		{
+			name:    "unknown hosts",
+			config:  Config{APIURL: "https://wiz.example.com/graphql", AuthURL: "https://auth.example.com/token"},
+			apiURL:  "https://wiz.example.com/graphql",
+			authURL: "https://auth.example.com/token",
+		},
+		{
+			name:   "mismatched environments",
+			config: Config{APIURL: "https://api.us1.app.wiz.io/graphql", AuthURL: "https://auth.gov.wiz.io/oauth/token"},
+			err:    "belongs to the commercial environment but auth_url https://auth.gov.wiz.io/oauth/token belongs to the gov environment",
+		},
+		{
+			name:   "environment does not match api_url",
+			config: Config{APIURL: "https://api.us1.app.wiz.io/graphql", Environment: "gov"},
+			err:    "belongs to the commercial environment",
+		},
+		{
+			name:   "unknown environment",
+			config: Config{DataCenter: "us1", Environment: "staging"},
+			err:    `unknown environment "staging"`,
+		},
+		{
+			name:   "no api_url or data center",
+			config: Config{},
+			err:    "either api_url or data_center is required",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			err := resolveURLs(&config)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if config.APIURL != tc.apiURL || config.AuthURL != tc.authURL {
+				t.Errorf("expected %s and %s, got %s and %s", tc.apiURL, tc.authURL, config.APIURL, config.AuthURL)
+			}
+		})
+	}
+}
+
+func TestHostEnvironment(t *testing.T) {
+	cases := []struct {
+		url      string
+		auth     bool
+		expected string
+	}{
+		{"https://api.us17.app.wiz.io/graphql", false, "commercial"},
+		{"https://api.us1.gov.wiz.io/graphql", false, "gov"},
+		{"https://api.us1.app.wiz.us/graphql", false, "fedramp"},
+		{"https://api.demo.demo.wiz.io/graphql", false, "demo"},
+		{"https://app.wiz.io/graphql", false, ""},
+		{"https://auth.app.wiz.io/oauth/token", true, "commercial"},
+		{"https://auth.wiz.io/oauth/token", true, "commercial"},
+		{"https://auth.gov.wiz.io/oauth/token", true, "gov"},
+		{"https://auth.app.wiz.io/oauth/token", false, ""},
+		{"https://api.us1.app.wiz.io/graphql", true, ""},
+		{"://invalid", false, ""},
+	}
+
+	for _, tc := range cases {
+		if got := hostEnvironment(tc.url, tc.auth); got != tc.expected {
+			t.Errorf("hostEnvironment(%q, %t) = %q, expected %q", tc.url, tc.auth, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Error kinds returned by the client. Use errors.Is to check the kind of an error
+// and errors.As with *APIError to get the details.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrValidation   = errors.New("validation failed")
+	ErrConflict     = errors.New("conflict")
+	ErrTransient    = errors.New("transient error")
+)
+
+// APIError is an error returned by the Wiz API or the transport to it
+type APIError struct {
+	// Kind is one of the Err* kinds, or nil if the error could not be classified
+	Kind error
+	// StatusCode is the HTTP status code of the response, if any
+	StatusCode int
+	// Code is the GraphQL errors[].extensions.code of the first error, if any
+	Code string
+	// Message is the error message returned by the API
+	Message string
+	// RetryAfter is the delay requested by the API through the Retry-After header
+	RetryAfter time.Duration
+	// Err is the underlying transport error, if any
+	Err error
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	switch {
+	case e.Code != "":
+		return fmt.Sprintf("graphql: %s (code: %s)", msg, e.Code)
+	case e.StatusCode != 0 && e.StatusCode != http.StatusOK:
+		return fmt.Sprintf("graphql: %s (status: %d)", msg, e.StatusCode)
+	default:
+		return fmt.Sprintf("graphql: %s", msg)
+	}
+}
+
+// Unwrap returns the error kind and the underlying transport error
+func (e *APIError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// graphQLCodeKinds maps GraphQL errors[].extensions.code values to error kinds
+var graphQLCodeKinds = map[string]error{
+	"NOT_FOUND":                 ErrNotFound,
+	"UNAUTHENTICATED":           ErrUnauthorized,
+	"UNAUTHORIZED":              ErrUnauthorized,
+	"FORBIDDEN":                 ErrUnauthorized,
+	"RATE_LIMIT_EXCEEDED":       ErrRateLimited,
+	"TOO_MANY_REQUESTS":         ErrRateLimited,
+	"BAD_USER_INPUT":            ErrValidation,
+	"GRAPHQL_VALIDATION_FAILED": ErrValidation,
+	"GRAPHQL_PARSE_FAILED":      ErrValidation,
+	"INVALID_INPUT":             ErrValidation,
+	"CONFLICT":                  ErrConflict,
+	"ALREADY_EXISTS":            ErrConflict,
+	"SERVICE_UNAVAILABLE":       ErrTransient,
+	"TIMEOUT":                   ErrTransient,
+}
+
+// kindFromGraphQLCode returns the error kind for a GraphQL error code
+func kindFromGraphQLCode(code string) error {
+	return graphQLCodeKinds[code]
+}
+
+// kindFromStatusCode returns the error kind for an HTTP status code
+func kindFromStatusCode(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// Mutations that must not be applied twice are only retried on errors
+		// raised before the API processed them, see retryUnsent
+		return ErrTransient
+	}
+	return nil
+}
+
+// newTransportError wraps an error returned by the HTTP client, classifying
+// network failures as transient. Context cancellation is returned unchanged.
+func newTransportError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	apiErr := &APIError{Err: err}
+
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		apiErr.Kind = ErrTransient
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE):
+		apiErr.Kind = ErrTransient
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		apiErr.Kind = ErrTransient
+	}
+
+	return apiErr
+}
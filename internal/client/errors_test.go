@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestNewTransportError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{
+			name: "timeout",
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			kind: ErrTransient,
+		},
+		{
+			name: "connection reset",
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			kind: ErrTransient,
+		},
+		{
+			name: "connection refused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			kind: ErrTransient,
+		},
+		{
+			name: "unexpected EOF",
+			err:  fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF),
+			kind: ErrTransient,
+		},
+		{
+			name: "unclassified",
+			err:  errors.New("tls: bad certificate"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newTransportError(tc.err)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *APIError, got %T", err)
+			}
+			if apiErr.Kind != tc.kind {
+				t.Errorf("expected kind %v, got %v", tc.kind, apiErr.Kind)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected the transport error to be wrapped")
+			}
+		})
+	}
+}
+
+func TestNewTransportErrorContext(t *testing.T) {
+	for _, err := range []error{context.Canceled, context.DeadlineExceeded} {
+		if got := newTransportError(err); got != err {
+			t.Errorf("expected %v unchanged, got %v", err, got)
+		}
+	}
+}
+
+func TestKindFromStatusCode(t *testing.T) {
+	cases := map[int]error{
+		http.StatusBadRequest:          ErrValidation,
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusForbidden:           ErrUnauthorized,
+		http.StatusNotFound:            ErrNotFound,
+		http.StatusConflict:            ErrConflict,
+		http.StatusTooManyRequests:     ErrRateLimited,
+		http.StatusInternalServerError: ErrTransient,
+		http.StatusBadGateway:          ErrTransient,
+		http.StatusServiceUnavailable:  ErrTransient,
+		http.StatusGatewayTimeout:      ErrTransient,
+		http.StatusOK:                  nil,
+		http.StatusNotImplemented:      nil,
+	}
+
+	for status, expected := range cases {
+		if got := kindFromStatusCode(status); got != expected {
+			t.Errorf("kindFromStatusCode(%d) = %v, expected %v", status, got, expected)
+		}
+	}
+}
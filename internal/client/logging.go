@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Log subsystems of the client, enabled with TF_LOG_PROVIDER_WIZ_CLIENT and
+// TF_LOG_PROVIDER_WIZ_AUTH
+const (
+	logSubsystemClient = "wiz_client"
+	logSubsystemAuth   = "wiz_auth"
+)
+
+// redactedValue replaces the values of sensitive fields in logs
+const redactedValue = "***"
+
+// withLogging returns a context with the log subsystems of the client. Secrets
+// in logged request variables are masked by redactVariables.
+func withLogging(ctx context.Context) context.Context {
+	for _, subsystem := range []string{logSubsystemClient, logSubsystemAuth} {
+		ctx = tflog.NewSubsystem(ctx, subsystem)
+	}
+	return ctx
+}
+
+// redactVariables returns a copy of GraphQL request variables that is safe to
+// log, with the values of sensitive fields replaced
+func redactVariables(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for k, fv := range value {
+			if IsSecretField(k) && fv != nil {
+				redacted[k] = redactedValue
+				continue
+			}
+			redacted[k] = redactVariables(fv)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for i, item := range value {
+			redacted[i] = redactVariables(item)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// graphQLOperationPattern matches the operation type and name of a GraphQL document
+var graphQLOperationPattern = regexp.MustCompile(`^\s*(query|mutation)\s+(\w+)`)
+
+// graphQLOperationName returns the name of the operation of a GraphQL document,
+// e.g. GetConnector, or "anonymous" if it has none
+func graphQLOperationName(query string) string {
+	if match := graphQLOperationPattern.FindStringSubmatch(query); match != nil {
+		return match[2]
+	}
+	return "anonymous"
+}
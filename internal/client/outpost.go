@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// OutpostConfig represents the cloud-specific configuration of an outpost. Only
+// the fields of the outpost's platform are set.
+type OutpostConfig struct {
+	// Typename is the GraphQL type of the config, e.g. OutpostAWSConfig
+	Typename       string `json:"__typename"`
+	Region         string `json:"region"`
+	RoleARN        string `json:"roleArn"`
+	ExternalID     string `json:"externalId"`
+	Environment    string `json:"environment"`
+	TenantID       string `json:"tenantId"`
+	SubscriptionID string `json:"subscriptionId"`
+	ClientID       string `json:"clientId"`
+}
+
+// Outpost represents an outpost returned by the outpost and outposts queries
+type Outpost struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Status      string         `json:"status"`
+	Enabled     bool           `json:"enabled"`
+	SelfManaged bool           `json:"selfManaged"`
+	Config      *OutpostConfig `json:"config"`
+}
+
+// CreatedOutpost represents the outpost returned by the createOutpost mutation.
+// The service account credentials are only returned by this mutation.
+type CreatedOutpost struct {
+	Outpost
+	ServiceAccount struct {
+		ClientID     string `json:"clientId"`
+		ClientSecret string `json:"clientSecret"`
+	} `json:"serviceAccount"`
+}
+
+// CreateOutpostResponse represents the response from the createOutpost mutation
+type CreateOutpostResponse struct {
+	CreateOutpost struct {
+		Outpost CreatedOutpost `json:"outpost"`
+	} `json:"createOutpost"`
+}
+
+// GetOutpostResponse represents the response from the outpost query
+type GetOutpostResponse struct {
+	Outpost *Outpost `json:"outpost"`
+}
+
+// UpdateOutpostResponse represents the response from the updateOutpost mutation
+type UpdateOutpostResponse struct {
+	UpdateOutpost struct {
+		Outpost struct {
+			ID string `json:"id"`
+		} `json:"outpost"`
+	} `json:"updateOutpost"`
+}
+
+// DeleteOutpostResponse represents the response from the deleteOutpost mutation
+type DeleteOutpostResponse struct {
+	DeleteOutpost struct {
+		Stub string `json:"_stub"`
+	} `json:"deleteOutpost"`
+}
+
+// ListOutpostsResponse represents the response from the outposts query
+type ListOutpostsResponse struct {
+	Outposts struct {
+		Nodes    []Outpost `json:"nodes"`
+		PageInfo PageInfo  `json:"pageInfo"`
+	} `json:"outposts"`
+}
+
+// CreateOutpostInput holds the fields used to create an outpost
+type CreateOutpostInput struct {
+	Name        string
+	Enabled     bool
+	SelfManaged bool
+	// Config is the OutpostConfigInput, keyed by platform, e.g. {"aws": {...}}
+	Config map[string]interface{}
+}
+
+// UpdateOutpostPatch holds the fields to change on an outpost. Empty and nil
+// fields are left out of the patch and keep their current value.
+type UpdateOutpostPatch struct {
+	Name    string
+	Enabled *bool
+	Config  map[string]interface{}
+}
+
+// outpostFields are the fields read for every outpost
+const outpostFields = `
+	id
+	name
+	status
+	enabled
+	selfManaged
+	config {
+		__typename
+		... on OutpostAWSConfig {
+			region
+			roleArn
+			externalId
+		}
+		... on OutpostAzureConfig {
+			region
+			environment
+			tenantId
+			subscriptionId
+			clientId
+		}
+	}
+`
+
+// CreateOutpost creates a new outpost
+func (c *Client) CreateOutpost(ctx context.Context, input CreateOutpostInput) (*CreatedOutpost, error) {
+	query := `
+		mutation CreateOutpost($input: CreateOutpostInput!) {
+			createOutpost(input: $input) {
+				outpost {
+					` + outpostFields + `
+					serviceAccount {
+						clientId
+						clientSecret
+					}
+				}
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"name":        input.Name,
+			"enabled":     input.Enabled,
+			"selfManaged": input.SelfManaged,
+			"config":      input.Config,
+		},
+	}
+
+	var response CreateOutpostResponse
+	err := c.retryUnsent(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating outpost: %w", err)
+	}
+
+	return &response.CreateOutpost.Outpost, nil
+}
+
+// GetOutpost gets an outpost by ID
+func (c *Client) GetOutpost(ctx context.Context, id string) (*Outpost, error) {
+	query := `
+		query GetOutpost($id: ID!) {
+			outpost(id: $id) {
+				` + outpostFields + `
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"id": id,
+	}
+
+	var response GetOutpostResponse
+	err := c.retryWithBackoff(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting outpost: %w", err)
+	}
+
+	if response.Outpost == nil {
+		return nil, &APIError{
+			Kind:    ErrNotFound,
+			Message: fmt.Sprintf("outpost not found: %s", id),
+		}
+	}
+
+	return response.Outpost, nil
+}
+
+// UpdateOutpost updates an existing outpost
+func (c *Client) UpdateOutpost(ctx context.Context, id string, outpostPatch UpdateOutpostPatch) error {
+	query := `
+		mutation UpdateOutpost($input: UpdateOutpostInput!) {
+			updateOutpost(input: $input) {
+				outpost {
+					id
+				}
+			}
+		}
+	`
+
+	patch := map[string]interface{}{}
+
+	if outpostPatch.Name != "" {
+		patch["name"] = outpostPatch.Name
+	}
+
+	if outpostPatch.Enabled != nil {
+		patch["enabled"] = *outpostPatch.Enabled
+	}
+
+	if outpostPatch.Config != nil {
+		patch["config"] = outpostPatch.Config
+	}
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":    id,
+			"patch": patch,
+		},
+	}
+
+	var response UpdateOutpostResponse
+	err := c.retryWithBackoff(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
+		return fmt.Errorf("error updating outpost: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOutpost deletes an outpost
+func (c *Client) DeleteOutpost(ctx context.Context, id string) error {
+	query := `
+		mutation DeleteOutpost($input: DeleteOutpostInput!) {
+			deleteOutpost(input: $input) {
+				_stub
+			}
+		}
+	`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id": id,
+		},
+	}
+
+	var response DeleteOutpostResponse
+	err := c.retryUnsent(ctx, func() error {
+		return c.RunQuery(ctx, query, variables, &response)
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting outpost: %w", err)
+	}
+
+	return nil
+}
+
+// listOutpostsPageSize is the number of outposts requested per page
+const listOutpostsPageSize = 100
+
+// ListOutposts lists the outposts whose name is exactly name, or all outposts if
+// name is empty, following the pagination cursors until all pages have been read
+func (c *Client) ListOutposts(ctx context.Context, name string) ([]Outpost, error) {
+	query := `
+		query ListOutposts($first: Int, $after: String, $filterBy: OutpostFilters) {
+			outposts(first: $first, after: $after, filterBy: $filterBy) {
+				nodes {
+					` + outpostFields + `
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}
+	`
+
+	// The search filter also matches names containing name, so only exact
+	// matches are kept
+	filterBy := map[string]interface{}{}
+	if name != "" {
+		filterBy["search"] = name
+	}
+
+	nodes, err := listPages(ctx, c, query, filterBy, listOutpostsPageSize, func(r *ListOutpostsResponse) ([]Outpost, PageInfo) {
+		return r.Outposts.Nodes, r.Outposts.PageInfo
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing outposts: %w", err)
+	}
+
+	var outposts []Outpost
+	for _, outpost := range nodes {
+		if name == "" || outpost.Name == name {
+			outposts = append(outposts, outpost)
+		}
+	}
+
+	return outposts, nil
+}
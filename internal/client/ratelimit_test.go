@@ -0,0 +1,54 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := newRateLimiter(2, 3)
+
+	// The burst is available at once
+	for i := 0; i < 3; i++ {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("request %d: expected no delay, got %s", i+1, delay)
+		}
+	}
+
+	// The next request waits for a token to refill at 2 per second
+	if delay := l.reserve(); delay <= 0 || delay > 500*time.Millisecond {
+		t.Fatalf("expected a delay of up to 500ms, got %s", delay)
+	}
+
+	// Tokens refill for the elapsed time, up to the burst
+	l.last = l.last.Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("request %d after refill: expected no delay, got %s", i+1, delay)
+		}
+	}
+	if delay := l.reserve(); delay <= 0 {
+		t.Fatalf("expected a delay after the burst, got %s", delay)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := newRateLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("request %d: expected no delay, got %s", i+1, delay)
+		}
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	l := newRateLimiter(0, 0)
+
+	l.Pause(time.Minute)
+	// A shorter pause does not shorten the current one
+	l.Pause(time.Second)
+
+	if delay := l.reserve(); delay <= 59*time.Second || delay > time.Minute {
+		t.Fatalf("expected a delay of about a minute, got %s", delay)
+	}
+}
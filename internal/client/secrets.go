@@ -0,0 +1,39 @@
+package client
+
+import "strings"
+
+// secretFieldFragments mark the keys of request fields holding secrets, such as
+// clientSecret, secretAccessKey or monitorEventHubConnectionString, when they
+// appear anywhere in the normalized key
+var secretFieldFragments = []string{
+	"secret",
+	"password",
+	"privatekey",
+	"serviceaccountkey",
+	"connectionstring",
+}
+
+// secretFieldSuffixes mark the keys of request fields holding secrets when they
+// end the normalized key, e.g. apiToken or personalAccessToken but not tokenUrl
+var secretFieldSuffixes = []string{
+	"token",
+}
+
+// IsSecretField reports whether a field key holds a secret, such as the fields
+// of the authParams and extraConfig of a connector. Their values are never
+// logged, and the provider only keeps their hashes in state. Keys are matched
+// regardless of case and of underscores and dashes.
+func IsSecretField(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, fragment := range secretFieldFragments {
+		if strings.Contains(normalized, fragment) {
+			return true
+		}
+	}
+	for _, suffix := range secretFieldSuffixes {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsSecretField(t *testing.T) {
+	cases := map[string]bool{
+		"clientSecret":                    true,
+		"ClientSecret":                    true,
+		"client_secret":                   true,
+		"secretKey":                       true,
+		"SECRET_KEY":                      true,
+		"access-token":                    true,
+		"token":                           true,
+		"monitorEventHubConnectionString": true,
+		"privateKeyPem":                   true,
+		"apiToken":                        true,
+		"personalAccessToken":             true,
+		"secretAccessKey":                 true,
+		"serviceAccountKey":               true,
+		"clientId":                        false,
+		"tenantId":                        false,
+		"tokenUrl":                        false,
+		"accessKeyId":                     false,
+		"roleArn":                         false,
+	}
+
+	for key, expected := range cases {
+		if got := IsSecretField(key); got != expected {
+			t.Errorf("IsSecretField(%q) = %t, expected %t", key, got, expected)
+		}
+	}
+}
+
+func TestRedactVariables(t *testing.T) {
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"name": "Azure",
+			"authParams": map[string]interface{}{
+				"clientId":     "client",
+				"ClientSecret": "secret",
+				"secretKey":    "key",
+				"password":     nil,
+			},
+			"list": []interface{}{
+				map[string]interface{}{"token": "t"},
+			},
+			"extraConfig": map[string]interface{}{
+				"auditLogMonitorEnabled":          true,
+				"monitorEventHubConnectionString": "Endpoint=sb://hub;SharedAccessKey=key",
+				"azureMonitorConfig": map[string]interface{}{
+					"eventHub": map[string]interface{}{
+						"name":             "hub",
+						"connectionString": "Endpoint=sb://hub;SharedAccessKey=key",
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"input": map[string]interface{}{
+			"name": "Azure",
+			"authParams": map[string]interface{}{
+				"clientId":     "client",
+				"ClientSecret": redactedValue,
+				"secretKey":    redactedValue,
+				"password":     nil,
+			},
+			"list": []interface{}{
+				map[string]interface{}{"token": redactedValue},
+			},
+			"extraConfig": map[string]interface{}{
+				"auditLogMonitorEnabled":          true,
+				"monitorEventHubConnectionString": redactedValue,
+				"azureMonitorConfig": map[string]interface{}{
+					"eventHub": map[string]interface{}{
+						"name":             "hub",
+						"connectionString": redactedValue,
+					},
+				},
+			},
+		},
+	}
+
+	if got := redactVariables(variables); !reflect.DeepEqual(got, expected) {
+		t.Errorf("redactVariables() = %v, expected %v", got, expected)
+	}
+}
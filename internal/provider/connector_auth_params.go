@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iancrichardson/terraform-provider-wiz/internal/client"
+)
+
+// connectorAuthPolicy describes which authParams fields of a connector type can be
+// changed on an existing connector
+type connectorAuthPolicy struct {
+	// rotatable fields can be changed in place, e.g. to rotate credentials
+	rotatable []string
+	// immutable fields identify the connected account and require a new connector
+	immutable []string
+}
+
+// connectorAuthPolicies holds the policies of the connector types with known
+// authParams. Connector types without a policy send every changed field.
+var connectorAuthPolicies = map[string]connectorAuthPolicy{
+	"aws": {
+		rotatable: []string{"roleArn", "externalId"},
+	},
+	"gcp": {
+		rotatable: []string{"serviceAccountKey"},
+		immutable: []string{"projectId", "organization_id", "folder_id", "isManagedIdentity"},
+	},
+	"azure": {
+		rotatable: []string{"clientId", "clientSecret"},
+		immutable: []string{"tenantId", "subscriptionId", "groupId", "environment", "isManagedIdentity"},
+	},
+}
+
+// knows reports whether a field is listed by the policy
+func (p connectorAuthPolicy) knows(k string) bool {
+	return containsString(p.rotatable, k) || containsString(p.immutable, k)
+}
+
+// isServerOnlyAuthParam reports whether an authParams field missing from the
+// configuration is one the API may add on its own. Only the fields known to the
+// policy of the connector type are treated as removed by the user.
+func isServerOnlyAuthParam(connectorType string, k string) bool {
+	policy, ok := connectorAuthPolicies[connectorType]
+	return !ok || !policy.knows(k)
+}
+
+// withoutServerOnlyAuthParams returns a copy of the prior authParams without the
+// fields that are missing from the desired authParams and only set by the API
+func withoutServerOnlyAuthParams(connectorType string, prior, desired map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(prior))
+	for k, v := range prior {
+		if _, ok := desired[k]; ok || !isServerOnlyAuthParam(connectorType, k) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// changedAuthParams returns the authParams fields whose value differs between the
+// prior and the desired authParams. Fields removed from the desired authParams are
+// included with a nil value, so the API clears them, unless their prior value is a
+// default. Secrets are compared by their hashes.
+func changedAuthParams(prior, desired map[string]interface{}) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for k, v := range desired {
+		pv, ok := prior[k]
+		if ok && (reflect.DeepEqual(pv, v) || (isSecretHash(pv) && pv == hashSecret(v))) {
+			continue
+		}
+		changed[k] = v
+	}
+	for k, pv := range prior {
+		if _, ok := desired[k]; !ok && !isDefaultJSONValue(pv) {
+			changed[k] = nil
+		}
+	}
+	return changed
+}
+
+// authParamsRotation returns the changed authParams fields that can be sent to
+// rotate the credentials of an existing connector. It fails if any changed field
+// cannot be changed in place. Fields only set by the API are not treated as
+// removed.
+func authParamsRotation(connectorType string, prior, desired map[string]interface{}) (map[string]interface{}, error) {
+	changed := changedAuthParams(withoutServerOnlyAuthParams(connectorType, prior, desired), desired)
+
+	policy, ok := connectorAuthPolicies[connectorType]
+	if !ok {
+		return changed, nil
+	}
+
+	var immutable, unsupported []string
+	for k := range changed {
+		switch {
+		case containsString(policy.rotatable, k):
+			continue
+		case containsString(policy.immutable, k):
+			immutable = append(immutable, k)
+		default:
+			unsupported = append(unsupported, k)
+		}
+	}
+
+	if len(immutable) > 0 || len(unsupported) > 0 {
+		sort.Strings(immutable)
+		sort.Strings(unsupported)
+
+		var reasons []string
+		if len(immutable) > 0 {
+			reasons = append(reasons, fmt.Sprintf("%s identify the connected account", strings.Join(immutable, ", ")))
+		}
+		if len(unsupported) > 0 {
+			reasons = append(reasons, fmt.Sprintf("%s cannot be updated through the API", strings.Join(unsupported, ", ")))
+		}
+		return nil, fmt.Errorf("cannot change auth params of %s connector in place: %s. Only %s can be rotated; replace the connector (e.g. with terraform apply -replace) to change the other fields",
+			connectorType, strings.Join(reasons, "; "), strings.Join(policy.rotatable, ", "))
+	}
+
+	return changed, nil
+}
+
+// secretHashPrefix marks authParams and extraConfig values that were replaced by
+// their hash
+const secretHashPrefix = "sha256:"
+
+// hashSecret returns the hash stored in state in place of a secret value
+func hashSecret(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+	if s == "" || strings.HasPrefix(s, secretHashPrefix) {
+		return s
+	}
+	sum := sha256.Sum256([]byte(s))
+	return secretHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// hashSecretStateFunc is a StateFunc storing the hash of a secret attribute
+func hashSecretStateFunc(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return hashSecret(v)
+}
+
+// isSecretHash reports whether a value is a hash stored in place of a secret
+func isSecretHash(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, secretHashPrefix)
+}
+
+// isSecretValue reports whether a field holds a secret value to hash. The secret
+// fields are the ones client.IsSecretField matches, and flags such as
+// useSecretManager are left alone.
+func isSecretValue(k string, v interface{}) bool {
+	if v == nil || !client.IsSecretField(k) {
+		return false
+	}
+	_, isBool := v.(bool)
+	return !isBool
+}
+
+// hashJSONSecrets returns a copy of a JSON value with the secret values replaced
+// by their hashes, at any depth
+func hashJSONSecrets(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		hashed := make(map[string]interface{}, len(value))
+		for k, fv := range value {
+			if isSecretValue(k, fv) {
+				hashed[k] = hashSecret(fv)
+				continue
+			}
+			hashed[k] = hashJSONSecrets(fv)
+		}
+		return hashed
+	case []interface{}:
+		hashed := make([]interface{}, len(value))
+		for i, item := range value {
+			hashed[i] = hashJSONSecrets(item)
+		}
+		return hashed
+	default:
+		return v
+	}
+}
+
+// normalizeSecretJSON is the StateFunc of auth_params and extra_config. It
+// normalizes the JSON and replaces secret values by their hashes, so secrets
+// never reach the state.
+func normalizeSecretJSON(v interface{}) string {
+	if v == nil || v.(string) == "" {
+		return ""
+	}
+	var obj interface{}
+	if err := json.Unmarshal([]byte(v.(string)), &obj); err != nil {
+		return v.(string)
+	}
+	jsonBytes, err := json.Marshal(hashJSONSecrets(obj))
+	if err != nil {
+		return v.(string)
+	}
+	return string(jsonBytes)
+}
+
+// mergeReadSecrets merges a JSON value returned by the API with the prior state.
+// Secrets are kept from the prior state, since the API may mask them, and hashed
+// if the API returned a value the state does not know yet.
+func mergeReadSecrets(read, prior interface{}) interface{} {
+	switch value := read.(type) {
+	case map[string]interface{}:
+		priorValue, _ := prior.(map[string]interface{})
+		merged := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			switch {
+			case isSecretValue(k, priorValue[k]):
+				merged[k] = hashSecret(priorValue[k])
+			case isSecretValue(k, v):
+				merged[k] = hashSecret(v)
+			default:
+				merged[k] = mergeReadSecrets(v, priorValue[k])
+			}
+		}
+		// Secrets the API does not return at all are kept from the prior state
+		for k, pv := range priorValue {
+			if _, ok := merged[k]; !ok && isSecretValue(k, pv) {
+				merged[k] = hashSecret(pv)
+			}
+		}
+		return merged
+	case []interface{}:
+		priorValue, _ := prior.([]interface{})
+		merged := make([]interface{}, len(value))
+		for i, item := range value {
+			var priorItem interface{}
+			if len(priorValue) == len(value) {
+				priorItem = priorValue[i]
+			}
+			merged[i] = mergeReadSecrets(item, priorItem)
+		}
+		return merged
+	default:
+		return read
+	}
+}
+
+// mergeReadAuthParams merges the authParams returned by the API with the prior
+// state, see mergeReadSecrets
+func mergeReadAuthParams(read, prior map[string]interface{}) map[string]interface{} {
+	merged, _ := mergeReadSecrets(read, prior).(map[string]interface{})
+	return merged
+}
+
+// withoutSecretHashes returns a copy of authParams without the values that are
+// hashes from the state, so the API keeps the secrets it already has
+func withoutSecretHashes(authParams map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(authParams))
+	for k, v := range authParams {
+		if !isSecretHash(v) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// writeOnlyAuthParams returns the authParams given through the write-only
+// auth_params_wo attribute, or nil if it is not set
+func writeOnlyAuthParams(d rawConfigReader) (map[string]interface{}, error) {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil, nil
+	}
+
+	v := rawConfig.GetAttr("auth_params_wo")
+	if v.IsNull() || !v.IsKnown() || v.AsString() == "" {
+		return nil, nil
+	}
+
+	var authParams map[string]interface{}
+	if err := json.Unmarshal([]byte(v.AsString()), &authParams); err != nil {
+		return nil, fmt.Errorf("error parsing auth_params_wo: %w", err)
+	}
+	return authParams, nil
+}
+
+// plannedAuthParams returns the authParams to compare with the prior state on
+// update. The write-only authParams are merged in when their version changed;
+// otherwise the fields they hold keep their prior value, since their secrets are
+// not sent again and must not be cleared.
+func plannedAuthParams(authParams, prior, writeOnly map[string]interface{}, sendWriteOnly bool) map[string]interface{} {
+	if sendWriteOnly {
+		return mergeAuthParams(authParams, writeOnly)
+	}
+
+	kept := make(map[string]interface{}, len(writeOnly))
+	for k := range writeOnly {
+		if pv, ok := prior[k]; ok {
+			kept[k] = pv
+		}
+	}
+	return mergeAuthParams(authParams, kept)
+}
+
+// mergeAuthParams returns authParams with the fields of override added on top
+func mergeAuthParams(authParams, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(authParams)+len(override))
+	for k, v := range authParams {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,354 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAuthParamsRotation(t *testing.T) {
+	cases := []struct {
+		name          string
+		connectorType string
+		prior         map[string]interface{}
+		desired       map[string]interface{}
+		expected      map[string]interface{}
+		err           string
+	}{
+		{
+			name:          "unchanged",
+			connectorType: "aws",
+			prior:         map[string]interface{}{"roleArn": "arn", "externalId": "id"},
+			desired:       map[string]interface{}{"roleArn": "arn", "externalId": "id"},
+			expected:      map[string]interface{}{},
+		},
+		{
+			name:          "rotated field",
+			connectorType: "aws",
+			prior:         map[string]interface{}{"roleArn": "arn", "externalId": "id"},
+			desired:       map[string]interface{}{"roleArn": "arn2", "externalId": "id"},
+			expected:      map[string]interface{}{"roleArn": "arn2"},
+		},
+		{
+			name:          "server only field",
+			connectorType: "aws",
+			prior:         map[string]interface{}{"roleArn": "arn", "externalId": "id", "accountId": "123456789012"},
+			desired:       map[string]interface{}{"roleArn": "arn2", "externalId": "id"},
+			expected:      map[string]interface{}{"roleArn": "arn2"},
+		},
+		{
+			name:          "server only field without other changes",
+			connectorType: "aws",
+			prior:         map[string]interface{}{"roleArn": "arn", "externalId": "id", "accountId": "123456789012"},
+			desired:       map[string]interface{}{"roleArn": "arn", "externalId": "id"},
+			expected:      map[string]interface{}{},
+		},
+		{
+			name:          "server only field of type without policy",
+			connectorType: "github",
+			prior:         map[string]interface{}{"installationId": "1", "organization": "org"},
+			desired:       map[string]interface{}{"installationId": "1"},
+			expected:      map[string]interface{}{},
+		},
+		{
+			name:          "removed rotatable field",
+			connectorType: "azure",
+			prior:         map[string]interface{}{"tenantId": "t", "clientId": "c", "clientSecret": hashSecret("s")},
+			desired:       map[string]interface{}{"tenantId": "t", "clientId": "c"},
+			expected:      map[string]interface{}{"clientSecret": nil},
+		},
+		{
+			name:          "removed immutable field",
+			connectorType: "azure",
+			prior:         map[string]interface{}{"tenantId": "t", "clientId": "c", "environment": "AzurePublicCloud"},
+			desired:       map[string]interface{}{"tenantId": "t", "clientId": "c"},
+			err:           "environment identify the connected account",
+		},
+		{
+			name:          "removed default field",
+			connectorType: "azure",
+			prior:         map[string]interface{}{"tenantId": "t", "clientId": "c", "isManagedIdentity": false},
+			desired:       map[string]interface{}{"tenantId": "t", "clientId": "c"},
+			expected:      map[string]interface{}{},
+		},
+		{
+			name:          "changed immutable field",
+			connectorType: "gcp",
+			prior:         map[string]interface{}{"projectId": "a"},
+			desired:       map[string]interface{}{"projectId": "b"},
+			err:           "projectId identify the connected account",
+		},
+		{
+			name:          "unsupported field",
+			connectorType: "aws",
+			prior:         map[string]interface{}{"roleArn": "arn"},
+			desired:       map[string]interface{}{"roleArn": "arn", "region": "us-east-1"},
+			err:           "region cannot be updated through the API",
+		},
+		{
+			name:          "unchanged secret compared by hash",
+			connectorType: "azure",
+			prior:         map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+			desired:       map[string]interface{}{"clientId": "c", "clientSecret": "s"},
+			expected:      map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rotation, err := authParamsRotation(tc.connectorType, tc.prior, tc.desired)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(rotation, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, rotation)
+			}
+		})
+	}
+}
+
+func TestWithoutServerOnlyAuthParams(t *testing.T) {
+	prior := map[string]interface{}{"roleArn": "arn", "externalId": "id", "accountId": "123456789012"}
+	desired := map[string]interface{}{"roleArn": "arn"}
+
+	// The server only field no longer makes the JSON differ, the removed
+	// externalId still does
+	got := withoutServerOnlyAuthParams("aws", prior, desired)
+	expected := map[string]interface{}{"roleArn": "arn", "externalId": "id"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	if connectorJSONEquivalent("", got, desired) {
+		t.Errorf("expected the removed externalId to be a change")
+	}
+
+	desired["externalId"] = "id"
+	if !connectorJSONEquivalent("", withoutServerOnlyAuthParams("aws", prior, desired), desired) {
+		t.Errorf("expected the server only accountId to be ignored")
+	}
+}
+
+func TestMergeReadAuthParams(t *testing.T) {
+	cases := []struct {
+		name     string
+		read     map[string]interface{}
+		prior    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "masked secret keeps prior hash",
+			read:     map[string]interface{}{"clientId": "c", "clientSecret": "****"},
+			prior:    map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+			expected: map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+		},
+		{
+			name:     "secret not returned keeps prior hash",
+			read:     map[string]interface{}{"clientId": "c"},
+			prior:    map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+			expected: map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+		},
+		{
+			name:     "new secret is hashed",
+			read:     map[string]interface{}{"clientId": "c", "clientSecret": "s"},
+			prior:    nil,
+			expected: map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+		},
+		{
+			name:     "changed non secret field is read",
+			read:     map[string]interface{}{"clientId": "other", "clientSecret": "****"},
+			prior:    map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s")},
+			expected: map[string]interface{}{"clientId": "other", "clientSecret": hashSecret("s")},
+		},
+		{
+			name:     "secret flags are not hashed",
+			read:     map[string]interface{}{"useSecretManager": true},
+			prior:    map[string]interface{}{"useSecretManager": false},
+			expected: map[string]interface{}{"useSecretManager": true},
+		},
+		{
+			name: "nested secrets",
+			read: map[string]interface{}{
+				"azureMonitorConfig": map[string]interface{}{
+					"eventHub": map[string]interface{}{"name": "hub", "connectionString": "****"},
+				},
+				"webhooks": []interface{}{
+					map[string]interface{}{"url": "https://example.com", "token": "t"},
+				},
+			},
+			prior: map[string]interface{}{
+				"azureMonitorConfig": map[string]interface{}{
+					"eventHub": map[string]interface{}{"name": "hub", "connectionString": hashSecret("Endpoint=sb://hub")},
+				},
+			},
+			expected: map[string]interface{}{
+				"azureMonitorConfig": map[string]interface{}{
+					"eventHub": map[string]interface{}{"name": "hub", "connectionString": hashSecret("Endpoint=sb://hub")},
+				},
+				"webhooks": []interface{}{
+					map[string]interface{}{"url": "https://example.com", "token": hashSecret("t")},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mergeReadAuthParams(tc.read, tc.prior); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlannedAuthParams(t *testing.T) {
+	prior := map[string]interface{}{"tenantId": "t", "clientId": "c", "clientSecret": hashSecret("s")}
+	writeOnly := map[string]interface{}{"clientSecret": "s2"}
+
+	cases := []struct {
+		name          string
+		authParams    map[string]interface{}
+		writeOnly     map[string]interface{}
+		sendWriteOnly bool
+		expected      map[string]interface{}
+		// sent is what TestConnectorConfig receives
+		sent map[string]interface{}
+	}{
+		{
+			name:          "write-only kept when version unchanged",
+			authParams:    map[string]interface{}{"tenantId": "t", "clientId": "c2"},
+			writeOnly:     writeOnly,
+			sendWriteOnly: false,
+			expected:      map[string]interface{}{"tenantId": "t", "clientId": "c2", "clientSecret": hashSecret("s")},
+			sent:          map[string]interface{}{"tenantId": "t", "clientId": "c2"},
+		},
+		{
+			name:          "write-only merged when version changed",
+			authParams:    map[string]interface{}{"tenantId": "t", "clientId": "c"},
+			writeOnly:     writeOnly,
+			sendWriteOnly: true,
+			expected:      map[string]interface{}{"tenantId": "t", "clientId": "c", "clientSecret": "s2"},
+			sent:          map[string]interface{}{"tenantId": "t", "clientId": "c", "clientSecret": "s2"},
+		},
+		{
+			name:          "write-only not set",
+			authParams:    map[string]interface{}{"tenantId": "t", "clientId": "c", "clientSecret": hashSecret("s")},
+			sendWriteOnly: false,
+			expected:      map[string]interface{}{"tenantId": "t", "clientId": "c", "clientSecret": hashSecret("s")},
+			sent:          map[string]interface{}{"tenantId": "t", "clientId": "c"},
+		},
+		{
+			name:          "write-only field missing from prior",
+			authParams:    map[string]interface{}{"tenantId": "t"},
+			writeOnly:     map[string]interface{}{"certificate": "pem"},
+			sendWriteOnly: false,
+			expected:      map[string]interface{}{"tenantId": "t"},
+			sent:          map[string]interface{}{"tenantId": "t"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := plannedAuthParams(tc.authParams, prior, tc.writeOnly, tc.sendWriteOnly)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+
+			// Hashes from the state are never sent to TestConnectorConfig
+			if sent := withoutSecretHashes(got); !reflect.DeepEqual(sent, tc.sent) {
+				t.Errorf("expected %v to be sent, got %v", tc.sent, sent)
+			}
+		})
+	}
+}
+
+func TestWithoutSecretHashes(t *testing.T) {
+	cases := []struct {
+		name       string
+		authParams map[string]interface{}
+		expected   map[string]interface{}
+	}{
+		{
+			name:       "nil",
+			authParams: nil,
+			expected:   map[string]interface{}{},
+		},
+		{
+			name:       "hashes removed",
+			authParams: map[string]interface{}{"clientId": "c", "clientSecret": hashSecret("s"), "privateKey": hashSecret("k")},
+			expected:   map[string]interface{}{"clientId": "c"},
+		},
+		{
+			name:       "plain secrets kept",
+			authParams: map[string]interface{}{"clientId": "c", "clientSecret": "s", "isManagedIdentity": false},
+			expected:   map[string]interface{}{"clientId": "c", "clientSecret": "s", "isManagedIdentity": false},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := withoutSecretHashes(tc.authParams); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHashBlockSecrets(t *testing.T) {
+	cases := []struct {
+		name     string
+		block    interface{}
+		expected []interface{}
+	}{
+		{
+			name:     "not set",
+			block:    []interface{}{},
+			expected: nil,
+		},
+		{
+			name: "secrets hashed",
+			block: []interface{}{map[string]interface{}{
+				"tenant_id":     "t",
+				"client_secret": "s",
+			}},
+			expected: []interface{}{map[string]interface{}{
+				"tenant_id":     "t",
+				"client_secret": hashSecret("s"),
+			}},
+		},
+		{
+			name: "hashes kept",
+			block: []interface{}{map[string]interface{}{
+				"service_account_key": hashSecret(`{"type":"service_account"}`),
+			}},
+			expected: []interface{}{map[string]interface{}{
+				"service_account_key": hashSecret(`{"type":"service_account"}`),
+			}},
+		},
+		{
+			name: "empty secret",
+			block: []interface{}{map[string]interface{}{
+				"client_id":     "c",
+				"client_secret": "",
+			}},
+			expected: []interface{}{map[string]interface{}{
+				"client_id":     "c",
+				"client_secret": "",
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := hashBlockSecrets(tc.block, connectorConfigSecretAttributes)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,589 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// connectorCloudTypes lists the connector types that have a typed configuration block.
+// The block name matches the connector type.
+var connectorCloudTypes = []string{"aws", "gcp", "azure"}
+
+func scheduledScanningSettingsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Scheduled security tool scanning settings",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"enabled": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Whether scheduled security tool scanning is enabled",
+				},
+				"public_buckets_scanning_enabled": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Whether public buckets are included in scheduled scanning",
+				},
+			},
+		},
+	}
+}
+
+func stringListSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: description,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+func awsConnectorConfigSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"role_arn": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ARN of the IAM role Wiz assumes in the customer account (authParams.roleArn)",
+			},
+			"external_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The external ID used when assuming the role (authParams.externalId)",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The AWS region of the connector",
+			},
+			"audit_log_monitor_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether CloudTrail log monitoring is enabled",
+			},
+			"cloudtrail_s3": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The S3 bucket CloudTrail logs are read from",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"bucket_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the CloudTrail bucket",
+						},
+						"prefix": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The key prefix of the CloudTrail logs",
+						},
+						"role_arn": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The ARN of the role used to read the bucket",
+						},
+					},
+				},
+			},
+			"scheduled_security_tool_scanning_settings": scheduledScanningSettingsSchema(),
+		},
+	}
+}
+
+func gcpConnectorConfigSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"project_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The GCP project ID (authParams.projectId)",
+			},
+			"organization_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The GCP organization ID for organization-level connectors",
+			},
+			"folder_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The GCP folder ID for folder-level connectors",
+			},
+			"service_account_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				StateFunc:   hashSecretStateFunc,
+				Description: "The service account key JSON (authParams.serviceAccountKey). Only its hash is stored in state",
+			},
+			"is_managed_identity": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether the connector uses a Wiz managed identity instead of a service account key",
+			},
+			"projects":          stringListSchema("Projects to include in scanning"),
+			"excluded_projects": stringListSchema("Projects to exclude from scanning"),
+			"included_folders":  stringListSchema("Folders to include in scanning"),
+			"excluded_folders":  stringListSchema("Folders to exclude from scanning"),
+			"audit_log_monitor_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether audit log monitoring is enabled",
+			},
+			"audit_logs_pub_sub": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The Pub/Sub topic audit logs are read from",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"topic_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The full name of the Pub/Sub topic",
+						},
+						"subscription_id": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The ID of the Pub/Sub subscription",
+						},
+					},
+				},
+			},
+			"scheduled_security_tool_scanning_settings": scheduledScanningSettingsSchema(),
+		},
+	}
+}
+
+func azureConnectorConfigSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"tenant_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Azure tenant ID (authParams.tenantId)",
+			},
+			"subscription_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Azure subscription ID for subscription-level connectors",
+			},
+			"group_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The management group ID for management-group-level connectors",
+			},
+			"environment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Azure cloud environment (e.g., AzurePublicCloud)",
+			},
+			"is_managed_identity": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether the connector uses a Wiz managed identity",
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The application (client) ID when not using a managed identity",
+			},
+			"client_secret": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				StateFunc:   hashSecretStateFunc,
+				Description: "The application client secret when not using a managed identity. Only its hash is stored in state",
+			},
+			"included_subscriptions":     stringListSchema("Subscriptions to include in scanning"),
+			"excluded_subscriptions":     stringListSchema("Subscriptions to exclude from scanning"),
+			"included_management_groups": stringListSchema("Management groups to include in scanning"),
+			"excluded_management_groups": stringListSchema("Management groups to exclude from scanning"),
+			"snapshots_resource_group_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The resource group used for disk snapshots",
+			},
+			"audit_log_monitor_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether Azure Monitor log monitoring is enabled",
+			},
+			"event_hub": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The Event Hub audit logs are read from",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"connection_method": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "How Wiz connects to the Event Hub (e.g., OAUTH_SINGLE_BY_NAME)",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The name of the Event Hub",
+						},
+						"namespace": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The Event Hub namespace",
+						},
+						"namespace_tag": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The tag used to discover Event Hub namespaces",
+						},
+					},
+				},
+			},
+			"scheduled_security_tool_scanning_settings": scheduledScanningSettingsSchema(),
+		},
+	}
+}
+
+// rawConfigReader gives access to the raw configuration, it is implemented by
+// both schema.ResourceData and schema.ResourceDiff
+type rawConfigReader interface {
+	GetRawConfig() cty.Value
+}
+
+// connectorConfigBlock returns the name of the typed configuration block set in
+// the configuration, or an empty string if the JSON attributes are used instead.
+func connectorConfigBlock(d rawConfigReader) string {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return ""
+	}
+
+	for _, t := range connectorCloudTypes {
+		v := rawConfig.GetAttr(t)
+		if v.IsKnown() && !v.IsNull() && v.LengthInt() > 0 {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// expandConnectorParams builds the authParams and extraConfig objects sent to the API,
+// either from the typed configuration block or from the JSON attributes.
+func expandConnectorParams(d *schema.ResourceData) (map[string]interface{}, map[string]interface{}, error) {
+	return expandConnectorParamsWith(d.Get("type").(string), connectorConfigBlock(d), d.Get)
+}
+
+// expandPriorConnectorParams builds the authParams and extraConfig objects from the
+// prior state, as they were before the planned changes.
+func expandPriorConnectorParams(d *schema.ResourceData) (map[string]interface{}, map[string]interface{}, error) {
+	get := func(key string) interface{} {
+		o, _ := d.GetChange(key)
+		return o
+	}
+	return expandConnectorParamsWith(d.Get("type").(string), connectorConfigBlock(d), get)
+}
+
+func expandConnectorParamsWith(connectorType string, block string, get func(string) interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	if block != "" {
+		if block != connectorType {
+			return nil, nil, fmt.Errorf("the %q block cannot be used with a connector of type %q", block, connectorType)
+		}
+
+		raw := firstBlock(get(block))
+		if raw == nil {
+			raw = map[string]interface{}{}
+		}
+		switch block {
+		case "aws":
+			authParams, extraConfig := expandAWSConnectorConfig(raw)
+			return authParams, extraConfig, nil
+		case "gcp":
+			authParams, extraConfig := expandGCPConnectorConfig(raw)
+			return authParams, extraConfig, nil
+		case "azure":
+			authParams, extraConfig := expandAzureConnectorConfig(raw)
+			return authParams, extraConfig, nil
+		}
+	}
+
+	// Parse auth_params JSON
+	var authParams map[string]interface{}
+	if authParamsStr, ok := get("auth_params").(string); ok && authParamsStr != "" {
+		if err := json.Unmarshal([]byte(authParamsStr), &authParams); err != nil {
+			return nil, nil, fmt.Errorf("error parsing auth_params: %w", err)
+		}
+	}
+
+	// Parse extra_config JSON if provided
+	var extraConfig map[string]interface{}
+	if extraConfigStr, ok := get("extra_config").(string); ok && extraConfigStr != "" {
+		if err := json.Unmarshal([]byte(extraConfigStr), &extraConfig); err != nil {
+			return nil, nil, fmt.Errorf("error parsing extra_config: %w", err)
+		}
+	}
+
+	return authParams, extraConfig, nil
+}
+
+func expandAWSConnectorConfig(raw map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	authParams := map[string]interface{}{}
+	setIfNotEmpty(authParams, "roleArn", raw["role_arn"])
+	setIfNotEmpty(authParams, "externalId", raw["external_id"])
+
+	extraConfig := map[string]interface{}{
+		"auditLogMonitorEnabled": raw["audit_log_monitor_enabled"],
+	}
+	setIfNotEmpty(extraConfig, "region", raw["region"])
+
+	if s3 := firstBlock(raw["cloudtrail_s3"]); s3 != nil {
+		bucket := map[string]interface{}{
+			"bucketName": s3["bucket_name"],
+		}
+		setIfNotEmpty(bucket, "prefix", s3["prefix"])
+		setIfNotEmpty(bucket, "roleArn", s3["role_arn"])
+		extraConfig["cloudtrailConfig"] = map[string]interface{}{
+			"s3": bucket,
+		}
+	}
+
+	expandScheduledScanningSettings(extraConfig, raw["scheduled_security_tool_scanning_settings"])
+
+	return authParams, extraConfig
+}
+
+func expandGCPConnectorConfig(raw map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	authParams := map[string]interface{}{
+		"isManagedIdentity": raw["is_managed_identity"],
+	}
+	setIfNotEmpty(authParams, "projectId", raw["project_id"])
+	setIfNotEmpty(authParams, "organization_id", raw["organization_id"])
+	setIfNotEmpty(authParams, "folder_id", raw["folder_id"])
+	setIfNotEmpty(authParams, "serviceAccountKey", raw["service_account_key"])
+
+	extraConfig := map[string]interface{}{
+		"projects":               expandStringList(raw["projects"]),
+		"excludedProjects":       expandStringList(raw["excluded_projects"]),
+		"includedFolders":        expandStringList(raw["included_folders"]),
+		"excludedFolders":        expandStringList(raw["excluded_folders"]),
+		"auditLogMonitorEnabled": raw["audit_log_monitor_enabled"],
+	}
+
+	if pubSub := firstBlock(raw["audit_logs_pub_sub"]); pubSub != nil {
+		extraConfig["auditLogsConfig"] = map[string]interface{}{
+			"pub_sub": map[string]interface{}{
+				"topicName":      pubSub["topic_name"],
+				"subscriptionID": pubSub["subscription_id"],
+			},
+		}
+	}
+
+	expandScheduledScanningSettings(extraConfig, raw["scheduled_security_tool_scanning_settings"])
+
+	return authParams, extraConfig
+}
+
+func expandAzureConnectorConfig(raw map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	authParams := map[string]interface{}{
+		"isManagedIdentity": raw["is_managed_identity"],
+	}
+	setIfNotEmpty(authParams, "tenantId", raw["tenant_id"])
+	setIfNotEmpty(authParams, "subscriptionId", raw["subscription_id"])
+	setIfNotEmpty(authParams, "groupId", raw["group_id"])
+	setIfNotEmpty(authParams, "environment", raw["environment"])
+	setIfNotEmpty(authParams, "clientId", raw["client_id"])
+	setIfNotEmpty(authParams, "clientSecret", raw["client_secret"])
+
+	extraConfig := map[string]interface{}{
+		"includedSubscriptions":    expandStringList(raw["included_subscriptions"]),
+		"excludedSubscriptions":    expandStringList(raw["excluded_subscriptions"]),
+		"includedManagementGroups": expandStringList(raw["included_management_groups"]),
+		"excludedManagementGroups": expandStringList(raw["excluded_management_groups"]),
+		"snapshotsResourceGroupId": raw["snapshots_resource_group_id"],
+		"auditLogMonitorEnabled":   raw["audit_log_monitor_enabled"],
+	}
+
+	if eventHub := firstBlock(raw["event_hub"]); eventHub != nil {
+		extraConfig["azureMonitorConfig"] = map[string]interface{}{
+			"eventHub": map[string]interface{}{
+				"connectionMethod": eventHub["connection_method"],
+				"name":             eventHub["name"],
+				"namespace":        eventHub["namespace"],
+				"namespaceTag":     eventHub["namespace_tag"],
+			},
+		}
+	}
+
+	expandScheduledScanningSettings(extraConfig, raw["scheduled_security_tool_scanning_settings"])
+
+	return authParams, extraConfig
+}
+
+func expandScheduledScanningSettings(extraConfig map[string]interface{}, v interface{}) {
+	settings := firstBlock(v)
+	if settings == nil {
+		return
+	}
+
+	extraConfig["scheduledSecurityToolScanningSettings"] = map[string]interface{}{
+		"enabled":                      settings["enabled"],
+		"publicBucketsScanningEnabled": settings["public_buckets_scanning_enabled"],
+	}
+}
+
+// flattenConnectorConfig converts the typed config and the authParams returned by
+// GetConnector into the block for the connector type. Values the API does not
+// return, such as secrets and log monitoring sources, are carried over from the
+// prior state. Secrets are only kept as hashes.
+func flattenConnectorConfig(connectorType string, config map[string]interface{}, authParams map[string]interface{}, prior map[string]interface{}) []interface{} {
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	if authParams == nil {
+		authParams = map[string]interface{}{}
+	}
+	if prior == nil {
+		prior = map[string]interface{}{}
+	}
+
+	var block map[string]interface{}
+	switch connectorType {
+	case "aws":
+		block = map[string]interface{}{
+			"role_arn":                  stringOr(config["customerRoleARN"], prior["role_arn"]),
+			"external_id":               prior["external_id"],
+			"region":                    stringOr(config["region"], prior["region"]),
+			"audit_log_monitor_enabled": prior["audit_log_monitor_enabled"],
+			"cloudtrail_s3":             prior["cloudtrail_s3"],
+		}
+	case "gcp":
+		block = map[string]interface{}{
+			"project_id":                stringOr(config["projectId"], prior["project_id"]),
+			"organization_id":           stringOr(config["organizationId"], prior["organization_id"]),
+			"folder_id":                 stringOr(config["folderId"], prior["folder_id"]),
+			"service_account_key":       hashSecretStateFunc(prior["service_account_key"]),
+			"is_managed_identity":       config["isManagedIdentity"],
+			"projects":                  config["projects"],
+			"excluded_projects":         config["excludedProjects"],
+			"included_folders":          config["includedFolders"],
+			"excluded_folders":          config["excludedFolders"],
+			"audit_log_monitor_enabled": config["auditLogMonitorEnabled"],
+			"audit_logs_pub_sub":        prior["audit_logs_pub_sub"],
+		}
+		if auditLogsConfig, ok := config["auditLogsConfig"].(map[string]interface{}); ok {
+			if pubSub, ok := auditLogsConfig["pub_sub"].(map[string]interface{}); ok {
+				block["audit_logs_pub_sub"] = []interface{}{
+					map[string]interface{}{
+						"topic_name":      pubSub["topicName"],
+						"subscription_id": pubSub["subscriptionID"],
+					},
+				}
+			}
+		}
+	case "azure":
+		block = map[string]interface{}{
+			"tenant_id":                   stringOr(config["tenantId"], prior["tenant_id"]),
+			"subscription_id":             stringOr(config["subscriptionId"], prior["subscription_id"]),
+			"group_id":                    stringOr(config["groupId"], prior["group_id"]),
+			"environment":                 stringOr(config["environment"], prior["environment"]),
+			"is_managed_identity":         config["isManagedIdentity"],
+			"client_id":                   stringOr(authParams["clientId"], prior["client_id"]),
+			"client_secret":               hashSecretStateFunc(prior["client_secret"]),
+			"included_subscriptions":      config["includedSubscriptions"],
+			"excluded_subscriptions":      config["excludedSubscriptions"],
+			"included_management_groups":  config["includedManagementGroups"],
+			"excluded_management_groups":  config["excludedManagementGroups"],
+			"snapshots_resource_group_id": config["snapshotsResourceGroupId"],
+			"audit_log_monitor_enabled":   config["auditLogMonitorEnabled"],
+			"event_hub":                   prior["event_hub"],
+		}
+		if monitorConfig, ok := config["azureMonitorConfig"].(map[string]interface{}); ok {
+			if eventHub, ok := monitorConfig["eventHub"].(map[string]interface{}); ok {
+				block["event_hub"] = []interface{}{
+					map[string]interface{}{
+						"connection_method": eventHub["connectionMethod"],
+						"name":              eventHub["name"],
+						"namespace":         eventHub["namespace"],
+						"namespace_tag":     eventHub["namespaceTag"],
+					},
+				}
+			}
+		}
+	default:
+		return nil
+	}
+
+	block["scheduled_security_tool_scanning_settings"] = prior["scheduled_security_tool_scanning_settings"]
+	if settings, ok := config["scheduledSecurityToolScanningSettings"].(map[string]interface{}); ok {
+		block["scheduled_security_tool_scanning_settings"] = []interface{}{
+			map[string]interface{}{
+				"enabled":                         settings["enabled"],
+				"public_buckets_scanning_enabled": settings["publicBucketsScanningEnabled"],
+			},
+		}
+	}
+
+	return []interface{}{block}
+}
+
+// connectorConfigSecretAttributes are the typed block attributes holding secrets
+var connectorConfigSecretAttributes = []string{"service_account_key", "client_secret"}
+
+// hashBlockSecrets returns a copy of a MaxItems: 1 block with the given secret
+// attributes replaced by their hashes
+func hashBlockSecrets(v interface{}, secretAttributes []string) []interface{} {
+	block := firstBlock(v)
+	if block == nil {
+		return nil
+	}
+
+	hashed := make(map[string]interface{}, len(block))
+	for k, v := range block {
+		if containsString(secretAttributes, k) {
+			v = hashSecretStateFunc(v)
+		}
+		hashed[k] = v
+	}
+	return []interface{}{hashed}
+}
+
+// firstBlock returns the single element of a MaxItems: 1 block, or nil if it is not set.
+func firstBlock(v interface{}) map[string]interface{} {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	block, _ := list[0].(map[string]interface{})
+	return block
+}
+
+func expandStringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func setIfNotEmpty(m map[string]interface{}, key string, v interface{}) {
+	if s, ok := v.(string); ok && s != "" {
+		m[key] = s
+	}
+}
+
+// stringOr returns v if it is a non-empty string and fallback otherwise.
+func stringOr(v interface{}, fallback interface{}) interface{} {
+	if s, ok := v.(string); ok && s != "" {
+		return s
+	}
+	return fallback
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func mustParseJSONObject(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %s", s, err)
+	}
+	return v
+}
+
+func TestConnectorConfigRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		// block is the typed block as given in the configuration
+		block map[string]interface{}
+		// authParams and extraConfig are the expected expansions of the block
+		authParams  string
+		extraConfig string
+		// readConfig and readAuthParams are returned by GetConnector
+		readConfig     string
+		readAuthParams string
+	}{
+		{
+			name: "aws",
+			block: map[string]interface{}{
+				"role_arn":                  "arn:aws:iam::123456789012:role/wiz",
+				"external_id":               "external",
+				"region":                    "us-east-1",
+				"audit_log_monitor_enabled": true,
+				"cloudtrail_s3": []interface{}{
+					map[string]interface{}{
+						"bucket_name": "trail",
+						"prefix":      "logs/",
+						"role_arn":    "",
+					},
+				},
+				"scheduled_security_tool_scanning_settings": []interface{}{
+					map[string]interface{}{
+						"enabled":                         true,
+						"public_buckets_scanning_enabled": false,
+					},
+				},
+			},
+			authParams: `{"roleArn":"arn:aws:iam::123456789012:role/wiz","externalId":"external"}`,
+			extraConfig: `{
+				"auditLogMonitorEnabled": true,
+				"region": "us-east-1",
+				"cloudtrailConfig": {"s3": {"bucketName": "trail", "prefix": "logs/"}},
+				"scheduledSecurityToolScanningSettings": {"enabled": true, "publicBucketsScanningEnabled": false}
+			}`,
+			// The external ID and the CloudTrail bucket are not returned
+			readConfig: `{
+				"customerRoleARN": "arn:aws:iam::123456789012:role/wiz",
+				"region": "us-east-1",
+				"scheduledSecurityToolScanningSettings": {"enabled": true, "publicBucketsScanningEnabled": false}
+			}`,
+			readAuthParams: `{"roleArn":"arn:aws:iam::123456789012:role/wiz"}`,
+		},
+		{
+			name: "gcp",
+			block: map[string]interface{}{
+				"project_id":                "project",
+				"organization_id":           "",
+				"folder_id":                 "",
+				"service_account_key":       `{"type":"service_account"}`,
+				"is_managed_identity":       false,
+				"projects":                  []interface{}{"a", "b"},
+				"excluded_projects":         []interface{}{},
+				"included_folders":          []interface{}{},
+				"excluded_folders":          []interface{}{"c"},
+				"audit_log_monitor_enabled": true,
+				"audit_logs_pub_sub": []interface{}{
+					map[string]interface{}{
+						"topic_name":      "topic",
+						"subscription_id": "subscription",
+					},
+				},
+				"scheduled_security_tool_scanning_settings": []interface{}{},
+			},
+			authParams: `{"isManagedIdentity":false,"projectId":"project","serviceAccountKey":"{\"type\":\"service_account\"}"}`,
+			extraConfig: `{
+				"projects": ["a", "b"],
+				"excludedProjects": [],
+				"includedFolders": [],
+				"excludedFolders": ["c"],
+				"auditLogMonitorEnabled": true,
+				"auditLogsConfig": {"pub_sub": {"topicName": "topic", "subscriptionID": "subscription"}}
+			}`,
+			// The service account key is masked
+			readConfig: `{
+				"projectId": "project",
+				"isManagedIdentity": false,
+				"projects": ["a", "b"],
+				"excludedProjects": [],
+				"includedFolders": [],
+				"excludedFolders": ["c"],
+				"auditLogMonitorEnabled": true,
+				"auditLogsConfig": {"pub_sub": {"topicName": "topic", "subscriptionID": "subscription"}}
+			}`,
+			readAuthParams: `{"projectId":"project","serviceAccountKey":"****"}`,
+		},
+		{
+			name: "azure",
+			block: map[string]interface{}{
+				"tenant_id":                   "tenant",
+				"subscription_id":             "subscription",
+				"group_id":                    "",
+				"environment":                 "AzurePublicCloud",
+				"is_managed_identity":         false,
+				"client_id":                   "client",
+				"client_secret":               "secret",
+				"included_subscriptions":      []interface{}{},
+				"excluded_subscriptions":      []interface{}{"x"},
+				"included_management_groups":  []interface{}{},
+				"excluded_management_groups":  []interface{}{},
+				"snapshots_resource_group_id": "snapshots",
+				"audit_log_monitor_enabled":   true,
+				"event_hub": []interface{}{
+					map[string]interface{}{
+						"connection_method": "OAUTH",
+						"name":              "hub",
+						"namespace":         "namespace",
+						"namespace_tag":     "",
+					},
+				},
+				"scheduled_security_tool_scanning_settings": []interface{}{},
+			},
+			authParams: `{
+				"isManagedIdentity": false,
+				"tenantId": "tenant",
+				"subscriptionId": "subscription",
+				"environment": "AzurePublicCloud",
+				"clientId": "client",
+				"clientSecret": "secret"
+			}`,
+			extraConfig: `{
+				"includedSubscriptions": [],
+				"excludedSubscriptions": ["x"],
+				"includedManagementGroups": [],
+				"excludedManagementGroups": [],
+				"snapshotsResourceGroupId": "snapshots",
+				"auditLogMonitorEnabled": true,
+				"azureMonitorConfig": {"eventHub": {"connectionMethod": "OAUTH", "name": "hub", "namespace": "namespace", "namespaceTag": ""}}
+			}`,
+			readConfig: `{
+				"tenantId": "tenant",
+				"subscriptionId": "subscription",
+				"environment": "AzurePublicCloud",
+				"isManagedIdentity": false,
+				"includedSubscriptions": [],
+				"excludedSubscriptions": ["x"],
+				"includedManagementGroups": [],
+				"excludedManagementGroups": [],
+				"snapshotsResourceGroupId": "snapshots",
+				"auditLogMonitorEnabled": true,
+				"monitorEventHubConnectionString": "Endpoint=sb://hub",
+				"azureMonitorConfig": {"eventHub": {"connectionMethod": "OAUTH", "name": "hub", "namespace": "namespace", "namespaceTag": ""}}
+			}`,
+			readAuthParams: `{"tenantId":"tenant","clientId":"client","clientSecret":"****"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authParams, extraConfig, err := expandConnectorParamsWith(tc.name, tc.name, func(key string) interface{} {
+				return []interface{}{tc.block}
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// Compare through JSON, as sent to the API
+			for _, c := range []struct {
+				name     string
+				got      map[string]interface{}
+				expected string
+			}{
+				{"authParams", authParams, tc.authParams},
+				{"extraConfig", extraConfig, tc.extraConfig},
+			} {
+				b, _ := json.Marshal(c.got)
+				if got := mustParseJSONObject(t, string(b)); !reflect.DeepEqual(got, mustParseJSONObject(t, c.expected)) {
+					t.Errorf("%s: expected %s, got %s", c.name, c.expected, b)
+				}
+			}
+
+			// The state holds the block with hashed secrets, which reading the
+			// connector back must reproduce
+			state := firstBlock(hashBlockSecrets([]interface{}{tc.block}, connectorConfigSecretAttributes))
+			read := flattenConnectorConfig(tc.name, mustParseJSONObject(t, tc.readConfig), mustParseJSONObject(t, tc.readAuthParams), state)
+			if !reflect.DeepEqual(firstBlock(read), state) {
+				t.Errorf("expected %v, got %v", state, firstBlock(read))
+			}
+		})
+	}
+}
+
+func TestFlattenConnectorConfigDrift(t *testing.T) {
+	prior := map[string]interface{}{
+		"tenant_id":     "tenant",
+		"client_id":     "client",
+		"client_secret": hashSecret("secret"),
+	}
+	config := map[string]interface{}{"tenantId": "tenant"}
+	authParams := map[string]interface{}{"clientId": "other-client", "clientSecret": "****"}
+
+	block := firstBlock(flattenConnectorConfig("azure", config, authParams, prior))
+	if block["client_id"] != "other-client" {
+		t.Errorf("expected client_id changed outside Terraform to be read, got %v", block["client_id"])
+	}
+	if block["client_secret"] != hashSecret("secret") {
+		t.Errorf("expected the prior client_secret hash, got %v", block["client_secret"])
+	}
+
+	// Values the API does not return are kept from the prior block
+	block = firstBlock(flattenConnectorConfig("azure", config, nil, prior))
+	if block["client_id"] != "client" {
+		t.Errorf("expected the prior client_id, got %v", block["client_id"])
+	}
+}
+
+func TestFlattenConnectorConfigUnknownType(t *testing.T) {
+	if block := flattenConnectorConfig("github", map[string]interface{}{}, nil, nil); block != nil {
+		t.Errorf("expected no block, got %v", block)
+	}
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// setLikeConnectorFields are the authParams and extraConfig fields holding lists
+// whose order has no meaning to the API
+var setLikeConnectorFields = []string{
+	"projects",
+	"excludedProjects",
+	"includedFolders",
+	"excludedFolders",
+	"includedSubscriptions",
+	"excludedSubscriptions",
+	"includedManagementGroups",
+	"excludedManagementGroups",
+	"includedAccounts",
+	"excludedAccounts",
+	"includedRegions",
+	"excludedRegions",
+	"regions",
+}
+
+// suppressEquivalentConnectorJSON is the DiffSuppressFunc of auth_params and
+// extra_config. It suppresses diffs between JSON documents the API treats the
+// same: null and missing keys, defaults populated by the server, auth params the
+// server adds that are unknown to the connector type's policy, and set-like lists
+// in a different order.
+func suppressEquivalentConnectorJSON(k, old, new string, d *schema.ResourceData) bool {
+	if old == new {
+		return true
+	}
+	if old == "" || new == "" {
+		return false
+	}
+
+	var prior, desired interface{}
+	if err := json.Unmarshal([]byte(old), &prior); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &desired); err != nil {
+		return false
+	}
+
+	// Fields the API adds to auth_params on its own are not a change
+	if k == "auth_params" && d != nil {
+		priorParams, priorOK := prior.(map[string]interface{})
+		desiredParams, desiredOK := desired.(map[string]interface{})
+		if priorOK && desiredOK {
+			prior = withoutServerOnlyAuthParams(d.Get("type").(string), priorParams, desiredParams)
+		}
+	}
+
+	return connectorJSONEquivalent("", prior, desired)
+}
+
+// connectorJSONEquivalent compares the prior value of a field, as read from the
+// API, with the desired value from the configuration
+func connectorJSONEquivalent(key string, prior, desired interface{}) bool {
+	// A missing or null value is the same as the default value
+	if prior == nil && isDefaultJSONValue(desired) {
+		return true
+	}
+	if desired == nil && isDefaultJSONValue(prior) {
+		return true
+	}
+
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		priorValue, ok := prior.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for k, v := range desiredValue {
+			if !connectorJSONEquivalent(k, priorValue[k], v) {
+				return false
+			}
+		}
+
+		// Fields only set on the server are ignored if they hold a default value
+		for k, v := range priorValue {
+			if _, ok := desiredValue[k]; !ok && !isDefaultJSONValue(v) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		priorValue, ok := prior.([]interface{})
+		if !ok || len(priorValue) != len(desiredValue) {
+			return false
+		}
+
+		if containsString(setLikeConnectorFields, key) {
+			return reflect.DeepEqual(sortedJSONList(priorValue), sortedJSONList(desiredValue))
+		}
+
+		for i := range desiredValue {
+			if !connectorJSONEquivalent("", priorValue[i], desiredValue[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return reflect.DeepEqual(prior, desired)
+	}
+}
+
+// isDefaultJSONValue reports whether a JSON value is null or the zero value of its type
+func isDefaultJSONValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !value
+	case string:
+		return value == ""
+	case float64:
+		return value == 0
+	case []interface{}:
+		return len(value) == 0
+	case map[string]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// sortedJSONList returns the elements of a list as sorted JSON strings, so lists
+// can be compared regardless of their order
+func sortedJSONList(list []interface{}) []string {
+	result := make([]string, 0, len(list))
+	for _, v := range list {
+		b, _ := json.Marshal(v)
+		result = append(result, string(b))
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConnectorJSONEquivalent(t *testing.T) {
+	cases := []struct {
+		name     string
+		prior    string
+		desired  string
+		expected bool
+	}{
+		{
+			name:     "equal",
+			prior:    `{"region":"us-east-1","enabled":true}`,
+			desired:  `{"enabled":true,"region":"us-east-1"}`,
+			expected: true,
+		},
+		{
+			name:     "null and missing key",
+			prior:    `{"region":"us-east-1","folderId":null}`,
+			desired:  `{"region":"us-east-1"}`,
+			expected: true,
+		},
+		{
+			name:     "missing and null key",
+			prior:    `{"region":"us-east-1"}`,
+			desired:  `{"region":"us-east-1","folderId":null}`,
+			expected: true,
+		},
+		{
+			name:     "null and default value",
+			prior:    `{"excludedProjects":null,"auditLogMonitorEnabled":null}`,
+			desired:  `{"excludedProjects":[],"auditLogMonitorEnabled":false}`,
+			expected: true,
+		},
+		{
+			name:     "server only default key",
+			prior:    `{"region":"us-east-1","auditLogMonitorEnabled":false,"excludedRegions":[]}`,
+			desired:  `{"region":"us-east-1"}`,
+			expected: true,
+		},
+		{
+			name:     "server only non-default key",
+			prior:    `{"region":"us-east-1","environment":"AzurePublicCloud"}`,
+			desired:  `{"region":"us-east-1"}`,
+			expected: false,
+		},
+		{
+			name:     "changed value",
+			prior:    `{"region":"us-east-1"}`,
+			desired:  `{"region":"eu-west-1"}`,
+			expected: false,
+		},
+		{
+			name:     "reordered set-like list",
+			prior:    `{"excludedSubscriptions":["b","a","c"]}`,
+			desired:  `{"excludedSubscriptions":["a","b","c"]}`,
+			expected: true,
+		},
+		{
+			name:     "different set-like list",
+			prior:    `{"excludedSubscriptions":["b","a"]}`,
+			desired:  `{"excludedSubscriptions":["a","b","c"]}`,
+			expected: false,
+		},
+		{
+			name:     "reordered ordered list",
+			prior:    `{"hosts":["b","a"]}`,
+			desired:  `{"hosts":["a","b"]}`,
+			expected: false,
+		},
+		{
+			name:     "nested objects",
+			prior:    `{"cloudtrailConfig":{"s3":{"bucketName":"logs","prefix":null}}}`,
+			desired:  `{"cloudtrailConfig":{"s3":{"bucketName":"logs"}}}`,
+			expected: true,
+		},
+		{
+			name:     "different types",
+			prior:    `{"projects":"a"}`,
+			desired:  `{"projects":["a"]}`,
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var prior, desired interface{}
+			if err := json.Unmarshal([]byte(tc.prior), &prior); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tc.desired), &desired); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := connectorJSONEquivalent("", prior, desired); got != tc.expected {
+				t.Errorf("connectorJSONEquivalent(%s, %s) = %t, expected %t", tc.prior, tc.desired, got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// connectorTypesJSON lists the supported connector types and the authParams
+// fields each of them requires
+//
+//go:embed connector_types.json
+var connectorTypesJSON []byte
+
+// connectorAuthRule is a set of authParams fields that must be set together
+type connectorAuthRule struct {
+	// Required fields must be set to a non-empty value
+	Required []string `json:"required"`
+	// True fields must be set to true
+	True []string `json:"true"`
+}
+
+// connectorTypeSchema describes the authParams a connector type requires: every
+// rule of Required and at least one rule of AnyOf, if any
+type connectorTypeSchema struct {
+	Required []string            `json:"required"`
+	AnyOf    []connectorAuthRule `json:"anyOf"`
+}
+
+var connectorTypeSchemas = mustParseConnectorTypes(connectorTypesJSON)
+
+func mustParseConnectorTypes(data []byte) map[string]connectorTypeSchema {
+	var schemas map[string]connectorTypeSchema
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		panic(fmt.Sprintf("invalid connector_types.json: %s", err))
+	}
+	return schemas
+}
+
+// connectorTypes returns the supported connector types in sorted order
+func connectorTypes() []string {
+	types := make([]string, 0, len(connectorTypeSchemas))
+	for t := range connectorTypeSchemas {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// missing returns the fields of the rule that are not set in authParams
+func (r connectorAuthRule) missing(authParams map[string]interface{}) []string {
+	var missing []string
+	for _, k := range r.Required {
+		if isDefaultJSONValue(authParams[k]) {
+			missing = append(missing, k)
+		}
+	}
+	for _, k := range r.True {
+		if v, _ := authParams[k].(bool); !v {
+			missing = append(missing, k+" = true")
+		}
+	}
+	return missing
+}
+
+func (r connectorAuthRule) String() string {
+	fields := append([]string{}, r.Required...)
+	for _, k := range r.True {
+		fields = append(fields, k+" = true")
+	}
+	return strings.Join(fields, " and ")
+}
+
+// validateConnectorAuthParams checks that authParams hold the fields required by
+// the connector type. Types without a schema are not checked.
+func validateConnectorAuthParams(connectorType string, authParams map[string]interface{}) error {
+	s, ok := connectorTypeSchemas[connectorType]
+	if !ok {
+		return nil
+	}
+
+	var problems []string
+	if missing := (connectorAuthRule{Required: s.Required}).missing(authParams); len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing %s", strings.Join(missing, ", ")))
+	}
+
+	if len(s.AnyOf) > 0 {
+		satisfied := false
+		alternatives := make([]string, 0, len(s.AnyOf))
+		for _, rule := range s.AnyOf {
+			if len(rule.missing(authParams)) == 0 {
+				satisfied = true
+				break
+			}
+			alternatives = append(alternatives, rule.String())
+		}
+		if !satisfied {
+			problems = append(problems, fmt.Sprintf("one of %s is required", strings.Join(alternatives, " or ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid auth params for %s connector: %s", connectorType, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateJSONObject is a ValidateDiagFunc for attributes holding a JSON object.
+// Syntax errors are reported with their line and column.
+func validateJSONObject(v interface{}, path cty.Path) diag.Diagnostics {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return nil
+	}
+
+	var obj interface{}
+	if err := json.Unmarshal([]byte(s), &obj); err != nil {
+		detail := err.Error()
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			line, column := jsonErrorPosition(s, syntaxErrorOffset(syntaxErr))
+			detail = fmt.Sprintf("%s at line %d, column %d", syntaxErr, line, column)
+		}
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid JSON",
+			Detail:        detail,
+			AttributePath: path,
+		}}
+	}
+
+	if _, ok := obj.(map[string]interface{}); !ok {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid JSON",
+			Detail:        "The value must be a JSON object",
+			AttributePath: path,
+		}}
+	}
+
+	return nil
+}
+
+// syntaxErrorOffset returns the offset of the invalid character of a JSON syntax
+// error. The offset of the error points past that character, or to the end of
+// the input when it ended too early.
+func syntaxErrorOffset(err *json.SyntaxError) int64 {
+	if err.Offset > 0 && err.Error() != "unexpected end of JSON input" {
+		return err.Offset - 1
+	}
+	return err.Offset
+}
+
+// jsonErrorPosition returns the 1-based line and column of a byte offset in s
+func jsonErrorPosition(s string, offset int64) (int, int) {
+	if offset > int64(len(s)) {
+		offset = int64(len(s))
+	}
+	before := s[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n")
+	return line, column
+}
+
+// customizeDiffConnectorAuthParams checks at plan time that the auth params hold
+// the fields required by the connector type, and that the changes to the auth
+// params of an existing connector can be made in place. Values that are not known
+// until apply are not checked.
+func customizeDiffConnectorAuthParams(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("type") {
+		return nil
+	}
+
+	// Existing connectors are only checked when their auth params change
+	if d.Id() != "" && !d.HasChanges("auth_params", "auth_params_wo_version", "aws", "gcp", "azure") {
+		return nil
+	}
+
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil
+	}
+
+	connectorType := d.Get("type").(string)
+	block := connectorConfigBlock(d)
+
+	var authParams map[string]interface{}
+	if block != "" {
+		if !rawConfig.GetAttr(block).IsWhollyKnown() {
+			return nil
+		}
+		var err error
+		authParams, _, err = expandConnectorParamsWith(connectorType, block, d.Get)
+		if err != nil {
+			return err
+		}
+	} else {
+		v := rawConfig.GetAttr("auth_params")
+		if !v.IsKnown() {
+			return nil
+		}
+		if !v.IsNull() && v.AsString() != "" {
+			if err := json.Unmarshal([]byte(v.AsString()), &authParams); err != nil {
+				return fmt.Errorf("error parsing auth_params: %w", err)
+			}
+		}
+	}
+
+	if !rawConfig.GetAttr("auth_params_wo").IsKnown() {
+		return nil
+	}
+	writeOnly, err := writeOnlyAuthParams(d)
+	if err != nil {
+		return err
+	}
+
+	if err := validateConnectorAuthParams(connectorType, mergeAuthParams(authParams, writeOnly)); err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	// Changes to fields that cannot be rotated in place are reported in the plan
+	// rather than failing the apply
+	priorAuthParams, _, err := expandConnectorParamsWith(connectorType, block, func(key string) interface{} {
+		o, _ := d.GetChange(key)
+		return o
+	})
+	if err != nil {
+		return err
+	}
+	desired := plannedAuthParams(authParams, priorAuthParams, writeOnly, d.HasChange("auth_params_wo_version"))
+	_, err = authParamsRotation(connectorType, priorAuthParams, desired)
+	return err
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONErrorPosition(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+	}{
+		{
+			name:   "single line",
+			input:  `{"roleArn": "arn", }`,
+			line:   1,
+			column: 20,
+		},
+		{
+			name:   "multiple lines",
+			input:  "{\n  \"roleArn\": \"arn\",\n  \"externalId\" \"id\"\n}",
+			line:   3,
+			column: 16,
+		},
+		{
+			name:   "unexpected end",
+			input:  "{\n  \"roleArn\": \"arn\",\n",
+			line:   3,
+			column: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var v interface{}
+			var syntaxErr *json.SyntaxError
+			if err := json.Unmarshal([]byte(tc.input), &v); !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a syntax error, got %v", err)
+			}
+
+			line, column := jsonErrorPosition(tc.input, syntaxErrorOffset(syntaxErr))
+			if line != tc.line || column != tc.column {
+				t.Errorf("expected line %d, column %d, got line %d, column %d", tc.line, tc.column, line, column)
+			}
+		})
+	}
+}
+
+func TestJSONErrorPositionPastEnd(t *testing.T) {
+	if line, column := jsonErrorPosition("{\n}", 10); line != 2 || column != 2 {
+		t.Errorf("expected line 2, column 2, got line %d, column %d", line, column)
+	}
+}
+
+func TestValidateJSONObject(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  string
+		detail string
+	}{
+		{name: "object", value: `{"roleArn": "arn"}`},
+		{name: "empty", value: ""},
+		{
+			name:   "syntax error",
+			value:  "{\n  \"roleArn\": \"arn\",\n  \"externalId\" \"id\"\n}",
+			detail: "invalid character '\"' after object key at line 3, column 16",
+		},
+		{name: "array", value: `["arn"]`, detail: "The value must be a JSON object"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := validateJSONObject(tc.value, nil)
+			if tc.detail == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Detail != tc.detail {
+				t.Fatalf("expected error %q, got %v", tc.detail, diags)
+			}
+		})
+	}
+}
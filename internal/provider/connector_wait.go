@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/iancrichardson/terraform-provider-wiz/internal/client"
+)
+
+// connectorFailureStatuses are the statuses of connectors that will not connect
+// without a change to their configuration or to the cloud-side permissions
+var connectorFailureStatuses = []string{"ERROR", "DISCONNECTED"}
+
+// connectorWaitingStatus is the state reported to the StateChangeConf while the
+// connector has neither reached a wanted status nor a failure status
+const connectorWaitingStatus = "waiting"
+
+// waitForConnectorStatus polls a connector until its status is one of the
+// statuses in wait_for_status. It fails if the connector reaches a failure status
+// that is not wanted, or if the timeout expires. It returns the connector as last
+// read, or nil if wait_for_status is not set.
+func waitForConnectorStatus(ctx context.Context, c *client.Client, d *schema.ResourceData, timeout time.Duration) (map[string]interface{}, error) {
+	target := expandStringList(d.Get("wait_for_status").(*schema.Set).List())
+	if len(target) == 0 {
+		return nil, nil
+	}
+	sort.Strings(target)
+
+	connectorID := d.Id()
+	lastStatus := ""
+
+	conf := &retry.StateChangeConf{
+		Pending: []string{connectorWaitingStatus},
+		Target:  target,
+		Refresh: func() (interface{}, string, error) {
+			connector, err := c.GetConnector(ctx, connectorID)
+			if err != nil {
+				if errors.Is(err, client.ErrNotFound) {
+					// A new connector may not be readable right away
+					return nil, "", nil
+				}
+				return nil, "", err
+			}
+
+			status, _ := connector["status"].(string)
+			lastStatus = status
+
+			switch {
+			case containsString(target, status):
+				return connector, status, nil
+			case containsString(connectorFailureStatuses, status):
+				return nil, "", fmt.Errorf("connector %s reached status %s", connectorID, status)
+			default:
+				return connector, connectorWaitingStatus, nil
+			}
+		},
+		Timeout:    timeout,
+		Delay:      2 * time.Second,
+		MinTimeout: 5 * time.Second,
+	}
+
+	connector, err := conf.WaitForStateContext(ctx)
+	if err != nil {
+		var timeoutErr *retry.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			err = fmt.Errorf("timed out waiting for connector %s to reach status %s, last status: %s",
+				connectorID, strings.Join(target, " or "), stringOr(lastStatus, "unknown"))
+			if timeoutErr.LastError != nil {
+				err = fmt.Errorf("%w, last error: %w", err, timeoutErr.LastError)
+			}
+			return nil, err
+		}
+		return nil, fmt.Errorf("error waiting for connector %s to reach status %s: %w",
+			connectorID, strings.Join(target, " or "), err)
+	}
+
+	return connector.(map[string]interface{}), nil
+}
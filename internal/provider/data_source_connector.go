@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceConnector() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceConnectorRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The ID of the connector. Conflicts with name",
+				ExactlyOneOf: []string{"id", "name"},
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The name of the connector. Requires type",
+				ExactlyOneOf: []string{"id", "name"},
+				RequiredWith: []string{"name", "type"},
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The type of the connector (e.g., azure, aws, gcp)",
+			},
+			"extra_config": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Extra configuration of the connector in JSON format. Secret fields hold hashes",
+			},
+			"aws": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Typed configuration of AWS connectors",
+				Elem:        computedResource(awsConnectorConfigSchema()),
+			},
+			"gcp": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Typed configuration of GCP connectors",
+				Elem:        computedResource(gcpConnectorConfigSchema()),
+			},
+			"azure": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Typed configuration of Azure connectors",
+				Elem:        computedResource(azureConnectorConfigSchema()),
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The current status of the connector",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the connector is enabled",
+			},
+			"last_activity": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The timestamp of the last activity for this connector",
+			},
+			"outpost_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the associated outpost",
+			},
+		},
+	}
+}
+
+func dataSourceConnectorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	var diags diag.Diagnostics
+
+	connectorID := d.Get("id").(string)
+
+	// Look the connector up by name and type if no ID was given
+	if connectorID == "" {
+		id, err := findConnectorByName(ctx, c, d.Get("type").(string), d.Get("name").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		connectorID = id
+	}
+
+	connector, err := c.GetConnector(ctx, connectorID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error getting connector: %w", err))
+	}
+
+	d.SetId(connectorID)
+
+	if err := setConnectorAttributes(d, connector); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// computedResource returns a copy of a resource schema with every attribute
+// computed, so resource blocks can be reused as data source attributes
+func computedResource(r *schema.Resource) *schema.Resource {
+	computed := make(map[string]*schema.Schema, len(r.Schema))
+	for k, v := range r.Schema {
+		s := *v
+		s.Optional = false
+		s.Required = false
+		s.Computed = true
+		s.Default = nil
+		s.MaxItems = 0
+		s.MinItems = 0
+		s.ValidateFunc = nil
+		s.ValidateDiagFunc = nil
+		s.DiffSuppressFunc = nil
+		s.StateFunc = nil
+		s.ConflictsWith = nil
+		s.ExactlyOneOf = nil
+		s.RequiredWith = nil
+		s.AtLeastOneOf = nil
+		if elem, ok := s.Elem.(*schema.Resource); ok {
+			s.Elem = computedResource(elem)
+		}
+		computed[k] = &s
+	}
+	return &schema.Resource{Schema: computed}
+}
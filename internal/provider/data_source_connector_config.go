@@ -20,9 +20,10 @@ func dataSourceConnectorConfig() *schema.Resource {
 				Description: "The type of the connector (e.g., azure, aws, gcp)",
 			},
 			"auth_params": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Authentication parameters for the connector in JSON format",
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Authentication parameters for the connector in JSON format",
+				ValidateDiagFunc: validateJSONObject,
 				StateFunc: func(v interface{}) string {
 					// Normalize the JSON string
 					var jsonObj interface{}
@@ -32,9 +33,10 @@ func dataSourceConnectorConfig() *schema.Resource {
 				},
 			},
 			"extra_config": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Extra configuration for the connector in JSON format",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Extra configuration for the connector in JSON format",
+				ValidateDiagFunc: validateJSONObject,
 				StateFunc: func(v interface{}) string {
 					if v == nil || v.(string) == "" {
 						return ""
@@ -56,12 +58,47 @@ func dataSourceConnectorConfig() *schema.Resource {
 				Computed:    true,
 				Description: "Whether the connector configuration is valid",
 			},
+			"errors": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The reason the test failed, followed by the errors of the failed checks",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"checks": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The individual checks run by the test",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the check",
+						},
+						"success": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the check passed",
+						},
+						"error": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Why the check failed",
+						},
+						"remediation": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "How to fix the failure",
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
 func dataSourceConnectorConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	var diags diag.Diagnostics
 
 	connectorType := d.Get("type").(string)
@@ -87,13 +124,30 @@ func dataSourceConnectorConfigRead(ctx context.Context, d *schema.ResourceData,
 	}
 
 	// Test the connector configuration
-	success, err := c.TestConnectorConfig(ctx, connectorType, authParams, extraConfig, id)
+	result, err := c.TestConnectorConfig(ctx, connectorType, authParams, extraConfig, id)
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("error testing connector configuration: %w", err))
 	}
 
 	// Set the computed values
-	if err := d.Set("success", success); err != nil {
+	if err := d.Set("success", result.Success); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("errors", result.Errors()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	checks := make([]interface{}, 0, len(result.Checks))
+	for _, check := range result.Checks {
+		checks = append(checks, map[string]interface{}{
+			"name":        check.Name,
+			"success":     check.Success,
+			"error":       check.Error,
+			"remediation": check.Remediation,
+		})
+	}
+	if err := d.Set("checks", checks); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -102,3 +156,41 @@ func dataSourceConnectorConfigRead(ctx context.Context, d *schema.ResourceData,
 
 	return diags
 }
+
+// connectorConfigTestDiagnostics converts a failed connector configuration test
+// into error diagnostics, one for the overall failure and one per failed check
+func connectorConfigTestDiagnostics(result *client.TestConnectorConfigResult) diag.Diagnostics {
+	detail := result.Error
+	if detail == "" {
+		detail = "The Wiz API did not return a reason for the failure."
+	}
+	if result.Remediation != "" {
+		detail += "\n\nRemediation: " + result.Remediation
+	}
+
+	diags := diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  "connector configuration test failed",
+			Detail:   detail,
+		},
+	}
+
+	for _, check := range result.Checks {
+		if check.Success {
+			continue
+		}
+
+		detail := check.Error
+		if check.Remediation != "" {
+			detail += "\n\nRemediation: " + check.Remediation
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("connector configuration check %q failed", check.Name),
+			Detail:   detail,
+		})
+	}
+
+	return diags
+}
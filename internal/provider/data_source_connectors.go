@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/iancrichardson/terraform-provider-wiz/internal/client"
+)
+
+func dataSourceConnectors() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceConnectorsRead,
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return connectors of this type (e.g., azure, aws, gcp)",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return connectors with this status (e.g., CONNECTED)",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return enabled or disabled connectors",
+			},
+			"outpost_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return connectors running through this outpost",
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only return connectors whose name matches this regular expression",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The IDs of the matching connectors",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"connectors": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching connectors",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the connector",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the connector",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the connector",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The current status of the connector",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the connector is enabled",
+						},
+						"last_activity": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The timestamp of the last activity for this connector",
+						},
+						"outpost_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the associated outpost",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceConnectorsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	var diags diag.Diagnostics
+
+	filter := client.ConnectorFilter{
+		Type:      d.Get("type").(string),
+		Status:    d.Get("status").(string),
+		OutpostID: d.Get("outpost_id").(string),
+	}
+
+	// enabled is only filtered on when it is set, false is a valid filter value
+	if v := d.GetRawConfig().GetAttr("enabled"); v.IsKnown() && !v.IsNull() {
+		enabled := v.True()
+		filter.Enabled = &enabled
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	connectors, err := c.ListConnectors(ctx, filter)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error listing connectors: %w", err))
+	}
+
+	ids := make([]string, 0, len(connectors))
+	results := make([]interface{}, 0, len(connectors))
+	for _, connector := range connectors {
+		if nameRegex != nil && !nameRegex.MatchString(connector.Name) {
+			continue
+		}
+
+		outpostID := ""
+		if connector.Outpost != nil {
+			outpostID = connector.Outpost.ID
+		}
+
+		ids = append(ids, connector.ID)
+		results = append(results, map[string]interface{}{
+			"id":            connector.ID,
+			"name":          connector.Name,
+			"type":          connector.Type.ID,
+			"status":        connector.Status,
+			"enabled":       connector.Enabled,
+			"last_activity": connector.LastActivity,
+			"outpost_id":    outpostID,
+		})
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("connectors", results); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Generate a unique ID for the data source from the filters
+	enabled := ""
+	if filter.Enabled != nil {
+		enabled = fmt.Sprintf("%t", *filter.Enabled)
+	}
+	d.SetId(strings.Join([]string{
+		filter.Type,
+		filter.Status,
+		enabled,
+		filter.OutpostID,
+		d.Get("name_regex").(string),
+	}, "-"))
+
+	return diags
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceOutpost() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOutpostRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The ID of the outpost. Conflicts with name",
+				ExactlyOneOf: []string{"id", "name"},
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The name of the outpost",
+				ExactlyOneOf: []string{"id", "name"},
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the outpost is enabled",
+			},
+			"self_managed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the outpost is deployed and operated in your own cloud account",
+			},
+			"aws": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Configuration of outposts deployed in AWS",
+				Elem:        computedResource(awsOutpostConfigSchema()),
+			},
+			"azure": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Configuration of outposts deployed in Azure",
+				Elem:        computedResource(azureOutpostConfigSchema()),
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The current status of the outpost",
+			},
+		},
+	}
+}
+
+func dataSourceOutpostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	var diags diag.Diagnostics
+
+	outpostID := d.Get("id").(string)
+
+	// Look the outpost up by name if no ID was given
+	if outpostID == "" {
+		id, err := findOutpostByName(ctx, c, d.Get("name").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		outpostID = id
+	}
+
+	outpost, err := c.GetOutpost(ctx, outpostID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error getting outpost: %w", err))
+	}
+
+	d.SetId(outpostID)
+
+	if err := setOutpostAttributes(d, outpost); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
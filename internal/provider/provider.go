@@ -5,45 +5,133 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/iancrichardson/terraform-provider-wiz/internal/client"
 )
 
+// providerMeta is passed to resources and data sources as their meta value
+type providerMeta struct {
+	client *client.Client
+
+	// testConnectorOnCreate and testConnectorOnUpdate control whether connector
+	// configurations are tested against the live API before they are written
+	testConnectorOnCreate bool
+	testConnectorOnUpdate bool
+
+	// defaultDeletionProtection is the deletion_protection of new connectors
+	// that do not set it
+	defaultDeletionProtection bool
+}
+
 // Provider returns a terraform.ResourceProvider.
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"client_id": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				Sensitive:   true,
 				Description: "The client ID for API operations",
 				DefaultFunc: schema.EnvDefaultFunc("WIZ_CLIENT_ID", nil),
 			},
 			"client_secret": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				Sensitive:   true,
 				Description: "The client secret for API operations",
 				DefaultFunc: schema.EnvDefaultFunc("WIZ_CLIENT_SECRET", nil),
 			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path of a JSON or INI file holding the client ID and secret of named profiles. Used when client_id and client_secret are not set",
+				DefaultFunc: schema.EnvDefaultFunc("WIZ_CREDENTIALS_FILE", nil),
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The profile of credentials_file to use",
+				DefaultFunc: schema.EnvDefaultFunc("WIZ_PROFILE", client.DefaultProfile),
+			},
+			"access_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "A pre-minted access token used instead of the client credentials. It is not refreshed, so it must outlive the run",
+				DefaultFunc: schema.EnvDefaultFunc("WIZ_ACCESS_TOKEN", nil),
+			},
+			"data_center": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The data center of the Wiz tenant (e.g., us1, us17, eu1), used to derive api_url",
+				DefaultFunc: schema.EnvDefaultFunc("WIZ_DATA_CENTER", nil),
+			},
+			"environment": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "The Wiz environment of the tenant, used to derive api_url and auth_url. One of commercial, gov, fedramp or demo. Defaults to the environment of api_url, or commercial",
+				DefaultFunc:      schema.EnvDefaultFunc("WIZ_ENVIRONMENT", nil),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(client.Environments(), false)),
+			},
 			"api_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "The URL of the Wiz GraphQL API",
-				DefaultFunc: schema.EnvDefaultFunc("WIZ_API_URL", "https://api.eu1.demo.wiz.io/graphql"),
+				Description: "The URL of the Wiz GraphQL API. Takes priority over data_center and environment",
+				DefaultFunc: schema.EnvDefaultFunc("WIZ_API_URL", nil),
 			},
 			"auth_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "The URL of the Wiz authentication endpoint",
-				DefaultFunc: schema.EnvDefaultFunc("WIZ_AUTH_URL", "https://auth.demo.wiz.io/oauth/token"),
+				Description: "The URL of the Wiz authentication endpoint. Takes priority over environment",
+				DefaultFunc: schema.EnvDefaultFunc("WIZ_AUTH_URL", nil),
+			},
+			"auth_provider": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          client.AuthProviderAuto,
+				Description:      "The identity provider of auth_url: cognito, auth0, or auto to detect it from the host of auth_url. Selects the audience of the token request",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(client.AuthProviders(), false)),
+			},
+			"requests_per_second": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Default:     0,
+				Description: "The average number of API requests per second shared by all operations. Defaults to 0, which disables the limit",
+			},
+			"request_burst": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     3,
+				Description: "The number of API requests that may be sent at once before requests_per_second applies. Only used when requests_per_second is set",
+			},
+			"test_connector_on_create": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to test the configuration of connectors against the live API before creating them",
+			},
+			"test_connector_on_update": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to test rotated connector credentials against the live API before updating connectors",
+			},
+			"default_deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "The deletion_protection of new connectors that do not set it",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"wiz_connector": resourceConnector(),
+			"wiz_outpost":   resourceOutpost(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
+			"wiz_connector":        dataSourceConnector(),
 			"wiz_connector_config": dataSourceConnectorConfig(),
+			"wiz_connectors":       dataSourceConnectors(),
+			"wiz_outpost":          dataSourceOutpost(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -53,10 +141,19 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	var diags diag.Diagnostics
 
 	config := &client.Config{
-		ClientID:     d.Get("client_id").(string),
-		ClientSecret: d.Get("client_secret").(string),
-		APIURL:       d.Get("api_url").(string),
-		AuthURL:      d.Get("auth_url").(string),
+		ClientID:        d.Get("client_id").(string),
+		ClientSecret:    d.Get("client_secret").(string),
+		CredentialsFile: d.Get("credentials_file").(string),
+		Profile:         d.Get("profile").(string),
+		AccessToken:     d.Get("access_token").(string),
+		APIURL:          d.Get("api_url").(string),
+		AuthURL:         d.Get("auth_url").(string),
+		DataCenter:      d.Get("data_center").(string),
+		Environment:     d.Get("environment").(string),
+		AuthProvider:    d.Get("auth_provider").(string),
+
+		RequestsPerSecond: d.Get("requests_per_second").(float64),
+		RequestBurst:      d.Get("request_burst").(int),
 	}
 
 	c, err := client.NewClient(config)
@@ -64,5 +161,11 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		return nil, diag.FromErr(err)
 	}
 
-	return c, diags
+	return &providerMeta{
+		client:                c,
+		testConnectorOnCreate: d.Get("test_connector_on_create").(bool),
+		testConnectorOnUpdate: d.Get("test_connector_on_update").(bool),
+
+		defaultDeletionProtection: d.Get("default_deletion_protection").(bool),
+	}, diags
 }
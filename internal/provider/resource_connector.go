@@ -3,32 +3,20 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/iancrichardson/terraform-provider-wiz/internal/client"
 )
 
-// Helper function to normalize JSON strings
-func normalizeJSON(v interface{}) string {
-	if v == nil || v.(string) == "" {
-		return ""
-	}
-	var jsonObj interface{}
-	if err := json.Unmarshal([]byte(v.(string)), &jsonObj); err != nil {
-		return v.(string)
-	}
-	jsonBytes, err := json.Marshal(jsonObj)
-	if err != nil {
-		return v.(string)
-	}
-	return string(jsonBytes)
-}
-
 // Helper function for deep comparison of maps
 func deepCompare(current, desired map[string]interface{}, ignoredFields []string) (bool, []string) {
 	changedFields := []string{}
@@ -100,40 +88,16 @@ func containsString(slice []string, s string) bool {
 	return false
 }
 
-// Generate a human-readable diff
-func generateDiff(current, desired map[string]interface{}) string {
-	var diff strings.Builder
-	diff.WriteString("Changes:\n")
-
-	// Check for changes in desired vs current
-	for k, desiredVal := range desired {
-		currentVal, exists := current[k]
-		if !exists {
-			diff.WriteString(fmt.Sprintf("+ %s: %v (added)\n", k, desiredVal))
-			continue
-		}
-
-		if !reflect.DeepEqual(currentVal, desiredVal) {
-			diff.WriteString(fmt.Sprintf("~ %s: %v -> %v\n", k, currentVal, desiredVal))
-		}
-	}
-
-	// Check for fields in current that are not in desired
-	for k, currentVal := range current {
-		if _, exists := desired[k]; !exists {
-			diff.WriteString(fmt.Sprintf("- %s: %v (removed)\n", k, currentVal))
-		}
-	}
-
-	return diff.String()
-}
-
 func resourceConnector() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceConnectorCreate,
 		ReadContext:   resourceConnectorRead,
 		UpdateContext: resourceConnectorUpdate,
 		DeleteContext: resourceConnectorDelete,
+		CustomizeDiff: customdiff.All(
+			customizeDiffConnectorAuthParams,
+			customizeDiffDeletionProtection,
+		),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -141,22 +105,106 @@ func resourceConnector() *schema.Resource {
 				Description: "The name of the connector",
 			},
 			"type": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The type of the connector (e.g., azure, aws, gcp)",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Description:      "The type of the connector (e.g., azure, aws, gcp)",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(connectorTypes(), false)),
 			},
 			"auth_params": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Authentication parameters for the connector in JSON format",
-				StateFunc:   normalizeJSON,
+				Type:                  schema.TypeString,
+				Optional:              true,
+				Computed:              true,
+				Sensitive:             true,
+				Description:           "Authentication parameters for the connector in JSON format. Secret fields are stored in state as hashes. Conflicts with the typed aws, gcp and azure blocks",
+				StateFunc:             normalizeSecretJSON,
+				ValidateDiagFunc:      validateJSONObject,
+				ExactlyOneOf:          []string{"auth_params", "aws", "gcp", "azure"},
+				DiffSuppressFunc:      suppressEquivalentConnectorJSON,
+				DiffSuppressOnRefresh: true,
+			},
+			"auth_params_wo": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				WriteOnly:        true,
+				Description:      "Secret authentication parameters in JSON format, merged over auth_params or the typed block. Never stored in plan or state. Requires Terraform 1.11 or later",
+				ValidateDiagFunc: validateJSONObject,
+			},
+			"auth_params_wo_version": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Change this value to send auth_params_wo again, e.g. to rotate the secrets it holds",
 			},
 			"extra_config": {
-				Type:        schema.TypeString,
+				Type:                  schema.TypeString,
+				Optional:              true,
+				Computed:              true,
+				Sensitive:             true,
+				Description:           "Extra configuration for the connector in JSON format. Secret fields are stored in state as hashes. Conflicts with the typed aws, gcp and azure blocks",
+				StateFunc:             normalizeSecretJSON,
+				ValidateDiagFunc:      validateJSONObject,
+				ConflictsWith:         []string{"aws", "gcp", "azure"},
+				DiffSuppressFunc:      suppressEquivalentConnectorJSON,
+				DiffSuppressOnRefresh: true,
+			},
+			"aws": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Computed:     true,
+				MaxItems:     1,
+				Description:  "Typed configuration for AWS connectors",
+				Elem:         awsConnectorConfigSchema(),
+				ExactlyOneOf: []string{"auth_params", "aws", "gcp", "azure"},
+			},
+			"gcp": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Computed:     true,
+				MaxItems:     1,
+				Description:  "Typed configuration for GCP connectors",
+				Elem:         gcpConnectorConfigSchema(),
+				ExactlyOneOf: []string{"auth_params", "aws", "gcp", "azure"},
+			},
+			"azure": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Computed:     true,
+				MaxItems:     1,
+				Description:  "Typed configuration for Azure connectors",
+				Elem:         azureConnectorConfigSchema(),
+				ExactlyOneOf: []string{"auth_params", "aws", "gcp", "azure"},
+			},
+			"skip_connection_test": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Extra configuration for the connector in JSON format",
-				StateFunc:   normalizeJSON,
+				Default:     false,
+				Description: "Skip testing the connector configuration against the live API before it is written, e.g. when the cloud-side permissions are created in the same apply",
+			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether destroying the connector fails when on_destroy is delete. Defaults to the provider's default_deletion_protection for new connectors",
+			},
+			"recreate_on_missing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Recreate the connector during an update if it was deleted after the plan was made, instead of failing",
+			},
+			"on_destroy": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          connectorOnDestroyDelete,
+				Description:      "What happens to the connector on destroy: delete it, disable it, or abandon it by only removing it from state",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{connectorOnDestroyDelete, connectorOnDestroyDisable, connectorOnDestroyAbandon}, false)),
+			},
+			"wait_for_status": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Wait after create and update until the status of the connector is one of these statuses (e.g., CONNECTED). Fails if the connector reaches ERROR or DISCONNECTED instead",
+				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
 			"status": {
 				Type:        schema.TypeString,
@@ -176,8 +224,22 @@ func resourceConnector() *schema.Resource {
 			},
 			"outpost_id": {
 				Type:        schema.TypeString,
+				Optional:    true,
 				Computed:    true,
-				Description: "The ID of the associated outpost",
+				ForceNew:    true,
+				Description: "The ID of the outpost the connector runs through. Defaults to the default outpost. Connectors cannot be moved to another outpost, so changing it creates a new connector",
+			},
+			"outpost_client_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The client ID of the outpost service account, only returned when the connector is created",
+			},
+			"outpost_client_secret": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The client secret of the outpost service account, only returned when the connector is created",
 			},
 		},
 		Timeouts: &schema.ResourceTimeout{
@@ -187,55 +249,99 @@ func resourceConnector() *schema.Resource {
 			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceConnectorImport,
 		},
 	}
 }
 
 func resourceConnectorCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	meta := m.(*providerMeta)
+	c := meta.client
 	var diags diag.Diagnostics
 
 	name := d.Get("name").(string)
 	connectorType := d.Get("type").(string)
 
-	// Parse auth_params JSON
-	var authParams map[string]interface{}
-	if err := json.Unmarshal([]byte(d.Get("auth_params").(string)), &authParams); err != nil {
-		return diag.FromErr(fmt.Errorf("error parsing auth_params: %w", err))
-	}
-
-	// Parse extra_config JSON if provided
-	var extraConfig map[string]interface{}
-	if extraConfigStr, ok := d.Get("extra_config").(string); ok && extraConfigStr != "" {
-		if err := json.Unmarshal([]byte(extraConfigStr), &extraConfig); err != nil {
-			return diag.FromErr(fmt.Errorf("error parsing extra_config: %w", err))
-		}
+	authParams, extraConfig, err := expandConnectorParams(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	// Test the connector configuration first
-	success, err := c.TestConnectorConfig(ctx, connectorType, authParams, extraConfig, "")
+	writeOnlyParams, err := writeOnlyAuthParams(d)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error testing connector configuration: %w", err))
+		return diag.FromErr(err)
 	}
+	authParams = mergeAuthParams(authParams, writeOnlyParams)
 
-	if !success {
-		return diag.FromErr(fmt.Errorf("connector configuration test failed"))
+	// Test the connector configuration first, unless it cannot pass yet
+	if meta.testConnectorOnCreate && !d.Get("skip_connection_test").(bool) {
+		result, err := c.TestConnectorConfig(ctx, connectorType, authParams, extraConfig, "")
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error testing connector configuration: %w", err))
+		}
+
+		if !result.Success {
+			return connectorConfigTestDiagnostics(result)
+		}
 	}
 
 	// Create the connector
-	id, err := c.CreateConnector(ctx, name, connectorType, authParams, extraConfig)
+	connector, err := c.CreateConnector(ctx, client.CreateConnectorInput{
+		Name:        name,
+		Type:        connectorType,
+		AuthParams:  authParams,
+		ExtraConfig: extraConfig,
+		Enabled:     d.Get("enabled").(bool),
+		OutpostID:   d.Get("outpost_id").(string),
+	})
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("error creating connector: %w", err))
 	}
 
-	d.SetId(id)
+	d.SetId(connector.ID)
+	tflog.Info(ctx, "Created connector", map[string]interface{}{
+		"connector_id": connector.ID,
+	})
+
+	// Only keep the hashes of the secrets in the typed block
+	if block := connectorConfigBlock(d); block != "" {
+		if err := d.Set(block, hashBlockSecrets(d.Get(block), connectorConfigSecretAttributes)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := d.Set("outpost_id", connector.Outpost.ID); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The outpost credentials are only returned on create, so keep them in state
+	serviceAccount := connector.Outpost.ServiceAccount
+	if err := d.Set("outpost_client_id", serviceAccount.ClientID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("outpost_client_secret", serviceAccount.ClientSecret); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Wait for the connector to connect, so dependent resources do not race it
+	current, err := waitForConnectorStatus(ctx, c, d, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if current != nil {
+		if err := d.Set("status", current["status"]); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("last_activity", current["lastActivity"]); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	return diags
 }
 
 func resourceConnectorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	var diags diag.Diagnostics
 
 	connectorID := d.Id()
@@ -243,93 +349,243 @@ func resourceConnectorRead(ctx context.Context, d *schema.ResourceData, m interf
 	connector, err := c.GetConnector(ctx, connectorID)
 	if err != nil {
 		// Check if the error indicates the connector was deleted or not found
-		if strings.Contains(err.Error(), "Connector was deleted") ||
-			strings.Contains(err.Error(), "connector not found") {
+		if errors.Is(err, client.ErrNotFound) {
 			// If the connector was deleted outside of Terraform, remove it from state
+			tflog.Warn(ctx, "Connector not found, removing it from state", map[string]interface{}{
+				"connector_id": connectorID,
+			})
 			d.SetId("")
 			return diags
 		}
 		return diag.FromErr(fmt.Errorf("error getting connector: %w", err))
 	}
 
-	// Set the resource data
-	if err := d.Set("name", connector["name"]); err != nil {
+	if err := setConnectorResourceAttributes(d, connector); err != nil {
 		return diag.FromErr(err)
 	}
 
-	if typeData, ok := connector["type"].(map[string]interface{}); ok {
-		if err := d.Set("type", typeData["id"]); err != nil {
-			return diag.FromErr(err)
+	return diags
+}
+
+// resourceConnectorImport imports a connector by ID or by "type/name", and fails
+// if the connector does not exist or the name is ambiguous
+func resourceConnectorImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*providerMeta).client
+
+	connectorID := d.Id()
+	if connectorType, name, ok := strings.Cut(connectorID, "/"); ok {
+		id, err := findConnectorByName(ctx, c, connectorType, name)
+		if err != nil {
+			return nil, err
 		}
+		connectorID = id
 	}
 
-	// Convert auth_params to JSON string
+	connector, err := c.GetConnector(ctx, connectorID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, fmt.Errorf("cannot import connector %q: no connector with this ID exists", connectorID)
+		}
+		return nil, fmt.Errorf("error getting connector: %w", err)
+	}
+
+	d.SetId(connectorID)
+
+	if err := setConnectorResourceAttributes(d, connector); err != nil {
+		return nil, err
+	}
+
+	// Attributes that only exist in the configuration get their defaults, so
+	// the plan after an import is empty
+	if err := d.Set("skip_connection_test", false); err != nil {
+		return nil, err
+	}
+	if err := d.Set("on_destroy", connectorOnDestroyDelete); err != nil {
+		return nil, err
+	}
+	if err := d.Set("recreate_on_missing", false); err != nil {
+		return nil, err
+	}
+	if err := d.Set("deletion_protection", m.(*providerMeta).defaultDeletionProtection); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// customizeDiffDeletionProtection enables deletion protection on new connectors
+// according to the provider's default, unless it is set in the configuration.
+// Connectors created before the attribute existed are left unprotected.
+func customizeDiffDeletionProtection(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta, ok := m.(*providerMeta)
+	if d.Id() != "" || !ok {
+		return nil
+	}
+
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() || !rawConfig.GetAttr("deletion_protection").IsNull() {
+		return nil
+	}
+
+	return d.SetNew("deletion_protection", meta.defaultDeletionProtection)
+}
+
+// findConnectorByName returns the ID of the only connector with the given type
+// and name, or an error if there is none or more than one
+func findConnectorByName(ctx context.Context, c *client.Client, connectorType string, name string) (string, error) {
+	connectors, err := c.ListConnectors(ctx, client.ConnectorFilter{
+		Name: name,
+		Type: connectorType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error looking up connector: %w", err)
+	}
+
+	ids := make([]string, 0, len(connectors))
+	for _, connector := range connectors {
+		ids = append(ids, connector.ID)
+	}
+	return uniqueIDByName(connectorType+" connector", name, ids)
+}
+
+// uniqueIDByName returns the only ID of the objects of the given kind found by
+// name, or an error if there is none or more than one
+func uniqueIDByName(kind string, name string, ids []string) (string, error) {
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no %s named %q was found", kind, name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d %ss named %q were found (%s), use the ID instead", len(ids), kind, name, strings.Join(ids, ", "))
+	}
+}
+
+// setConnectorResourceAttributes sets all attributes of the wiz_connector resource
+// from a connector returned by GetConnector
+func setConnectorResourceAttributes(d *schema.ResourceData, connector map[string]interface{}) error {
+	// Convert auth_params to JSON string, keeping secrets out of the state
 	if authParams, ok := connector["authParams"]; ok && authParams != nil {
+		if readParams, ok := authParams.(map[string]interface{}); ok {
+			var priorParams map[string]interface{}
+			if priorStr, ok := d.Get("auth_params").(string); ok && priorStr != "" {
+				_ = json.Unmarshal([]byte(priorStr), &priorParams)
+			}
+			authParams = mergeReadAuthParams(readParams, priorParams)
+		}
+
 		authParamsJSON, err := json.Marshal(authParams)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("error marshaling auth_params: %w", err))
+			return fmt.Errorf("error marshaling auth_params: %w", err)
 		}
 		if err := d.Set("auth_params", string(authParamsJSON)); err != nil {
-			return diag.FromErr(err)
+			return err
+		}
+	}
+
+	return setConnectorAttributes(d, connector)
+}
+
+// setConnectorAttributes sets the attributes shared by the wiz_connector resource
+// and data source from a connector returned by GetConnector
+func setConnectorAttributes(d *schema.ResourceData, connector map[string]interface{}) error {
+	if err := d.Set("name", connector["name"]); err != nil {
+		return err
+	}
+
+	if typeData, ok := connector["type"].(map[string]interface{}); ok {
+		if err := d.Set("type", typeData["id"]); err != nil {
+			return err
 		}
 	}
 
-	// Convert extra_config to JSON string
+	// Convert extra_config to JSON string, keeping secrets such as event hub
+	// connection strings out of the state
 	if extraConfig, ok := connector["extraConfig"]; ok && extraConfig != nil {
-		extraConfigJSON, err := json.Marshal(extraConfig)
+		var priorExtraConfig interface{}
+		if priorStr, ok := d.Get("extra_config").(string); ok && priorStr != "" {
+			_ = json.Unmarshal([]byte(priorStr), &priorExtraConfig)
+		}
+
+		extraConfigJSON, err := json.Marshal(mergeReadSecrets(extraConfig, priorExtraConfig))
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("error marshaling extra_config: %w", err))
+			return fmt.Errorf("error marshaling extra_config: %w", err)
 		}
 		if err := d.Set("extra_config", string(extraConfigJSON)); err != nil {
-			return diag.FromErr(err)
+			return err
+		}
+	}
+
+	// Set the typed configuration block for the connector type from the typed config
+	if typeData, ok := connector["type"].(map[string]interface{}); ok {
+		connectorType, _ := typeData["id"].(string)
+		config, _ := connector["config"].(map[string]interface{})
+		authParams, _ := connector["authParams"].(map[string]interface{})
+		for _, t := range connectorCloudTypes {
+			var block []interface{}
+			if t == connectorType {
+				block = flattenConnectorConfig(t, config, authParams, firstBlock(d.Get(t)))
+			}
+			if err := d.Set(t, block); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Set additional fields
 	if status, ok := connector["status"].(string); ok {
 		if err := d.Set("status", status); err != nil {
-			return diag.FromErr(err)
+			return err
 		}
 	}
 
 	if enabled, ok := connector["enabled"].(bool); ok {
 		if err := d.Set("enabled", enabled); err != nil {
-			return diag.FromErr(err)
+			return err
 		}
 	}
 
 	if lastActivity, ok := connector["lastActivity"].(string); ok {
 		if err := d.Set("last_activity", lastActivity); err != nil {
-			return diag.FromErr(err)
+			return err
 		}
 	}
 
-	// Set outpost_id if available
+	// Always set outpost_id, so a connector moved to another outpost shows up as drift
+	outpostID := ""
 	if outpost, ok := connector["outpost"].(map[string]interface{}); ok && outpost != nil {
-		if outpostID, ok := outpost["id"].(string); ok {
-			if err := d.Set("outpost_id", outpostID); err != nil {
-				return diag.FromErr(err)
-			}
-		}
+		outpostID, _ = outpost["id"].(string)
+	}
+	if err := d.Set("outpost_id", outpostID); err != nil {
+		return err
 	}
 
-	return diags
+	return nil
 }
 
 func resourceConnectorUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	meta := m.(*providerMeta)
+	c := meta.client
 
 	// Get current connector state
 	connectorID := d.Id()
 	currentConnector, err := c.GetConnector(ctx, connectorID)
 	if err != nil {
 		// Check if the error indicates the connector was deleted or not found
-		if strings.Contains(err.Error(), "Connector was deleted") ||
-			strings.Contains(err.Error(), "connector not found") {
-			// If the connector was deleted outside of Terraform, remove it from state
-			// and recreate it
-			d.SetId("")
-			return resourceConnectorCreate(ctx, d, m)
+		if errors.Is(err, client.ErrNotFound) {
+			// Read removes missing connectors from state, so the plan shows their
+			// creation. A connector only goes missing here if it was deleted after
+			// the plan, which is not recreated unless asked for.
+			if d.Get("recreate_on_missing").(bool) {
+				d.SetId("")
+				return resourceConnectorCreate(ctx, d, m)
+			}
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Connector no longer exists",
+				Detail: fmt.Sprintf("Connector %s was deleted after the plan was made. Run terraform plan again to plan its creation, or set recreate_on_missing = true to recreate it during the update.",
+					connectorID),
+			}}
 		}
 		return diag.FromErr(fmt.Errorf("error getting current connector state: %w", err))
 	}
@@ -337,38 +593,89 @@ func resourceConnectorUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	// Get desired state from schema
 	name := d.Get("name").(string)
 
-	// For auth_params, we'll use an empty object to avoid updating sensitive fields
-	// This prevents the API from rejecting updates to sensitive fields
+	// Rotate credentials if the auth params changed. Only the fields that can be
+	// changed in place are sent, after testing the new auth params against the
+	// existing connector. Changes to other fields were rejected at plan time.
 	var authParams map[string]interface{}
-	if d.HasChange("auth_params") {
-		// Log that we're excluding sensitive fields
-		fmt.Printf("Excluding sensitive auth_params fields from update for connector %s\n", connectorID)
-		// Use an empty object for auth_params
-		authParams = map[string]interface{}{}
+	block := connectorConfigBlock(d)
+	if (block != "" && d.HasChange(block)) || (block == "" && d.HasChange("auth_params")) || d.HasChange("auth_params_wo_version") {
+		configAuthParams, desiredExtraConfig, err := expandConnectorParams(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		priorAuthParams, _, err := expandPriorConnectorParams(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		writeOnlyParams, err := writeOnlyAuthParams(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		// Write-only secrets are only sent when their version changed
+		desiredAuthParams := plannedAuthParams(configAuthParams, priorAuthParams, writeOnlyParams, d.HasChange("auth_params_wo_version"))
+
+		connectorType := d.Get("type").(string)
+		rotation, err := authParamsRotation(connectorType, priorAuthParams, desiredAuthParams)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if len(rotation) > 0 {
+			// Secrets only known by their hash are left out, the API tests them
+			// with the values stored on the connector
+			if meta.testConnectorOnUpdate && !d.Get("skip_connection_test").(bool) {
+				result, err := c.TestConnectorConfig(ctx, connectorType, withoutSecretHashes(desiredAuthParams), desiredExtraConfig, connectorID)
+				if err != nil {
+					return diag.FromErr(fmt.Errorf("error testing rotated connector credentials: %w", err))
+				}
+				if !result.Success {
+					return connectorConfigTestDiagnostics(result)
+				}
+			}
+			authParams = rotation
+		}
 	}
 
 	var extraConfig map[string]interface{}
-	if d.HasChange("extra_config") {
+	if block != "" {
+		// Typed blocks carry auth params and extra config together, so the extra
+		// config is sent whenever the block changed
+		if d.HasChange(block) {
+			_, extraConfig, err = expandConnectorParams(d)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+	} else if d.HasChange("extra_config") {
 		if extraConfigStr, ok := d.Get("extra_config").(string); ok && extraConfigStr != "" {
 			if err := json.Unmarshal([]byte(extraConfigStr), &extraConfig); err != nil {
 				return diag.FromErr(fmt.Errorf("error parsing extra_config: %w", err))
 			}
-			// Log the extra_config changes
-			fmt.Printf("Parsed extra_config for connector %s\n", connectorID)
+			tflog.Debug(ctx, "Parsed extra_config", map[string]interface{}{
+				"connector_id": connectorID,
+			})
 		}
 	}
 
+	// Only send enabled when it changed, so it can be toggled on its own
+	var enabled *bool
+	if d.HasChange("enabled") {
+		v := d.Get("enabled").(bool)
+		enabled = &v
+	}
+
 	// Build desired state for comparison
 	desiredState := map[string]interface{}{
-		"name": name,
+		"name":    name,
+		"enabled": d.Get("enabled").(bool),
 	}
 
-	// Add auth_params to desired state (empty object if changed)
+	// Add the rotated auth params to desired state. The current auth params are not
+	// compared, since the API does not return secrets.
 	if authParams != nil {
 		desiredState["authParams"] = authParams
 	}
-	// We intentionally don't include auth_params from currentConnector
-	// to prevent false positives in the comparison
 
 	if extraConfig != nil {
 		desiredState["extraConfig"] = extraConfig
@@ -380,40 +687,87 @@ func resourceConnectorUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	ignoredFields := []string{"id", "status", "lastActivity", "outpost", "type", "config"}
 
 	// Compare current and desired state
-	equal, _ := deepCompare(currentConnector, desiredState, ignoredFields)
+	equal, changedFields := deepCompare(currentConnector, desiredState, ignoredFields)
 
 	// Only update if there are changes
 	if !equal {
-		// Log the changes
-		diff := generateDiff(currentConnector, desiredState)
-		fmt.Printf("Updating connector %s with changes: %s\n", connectorID, diff)
+		// Only the names of the changed fields are logged, their values may be secrets
+		tflog.Info(ctx, "Updating connector", map[string]interface{}{
+			"connector_id":   connectorID,
+			"changed_fields": changedFields,
+		})
 
 		// Update the connector
-		if err := c.UpdateConnector(ctx, connectorID, name, authParams, extraConfig); err != nil {
+		patch := client.UpdateConnectorPatch{
+			Name:        name,
+			AuthParams:  authParams,
+			ExtraConfig: extraConfig,
+			Enabled:     enabled,
+		}
+		if err := c.UpdateConnector(ctx, connectorID, patch); err != nil {
 			return diag.FromErr(fmt.Errorf("error updating connector: %w", err))
 		}
 	} else {
-		fmt.Printf("No changes detected for connector %s\n", connectorID)
+		tflog.Debug(ctx, "No changes detected for connector", map[string]interface{}{
+			"connector_id": connectorID,
+		})
+	}
+
+	if _, err := waitForConnectorStatus(ctx, c, d, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
 	}
 
 	return resourceConnectorRead(ctx, d, m)
 }
 
+// What happens to a connector on destroy, see on_destroy
+const (
+	connectorOnDestroyDelete  = "delete"
+	connectorOnDestroyDisable = "disable"
+	connectorOnDestroyAbandon = "abandon"
+)
+
 func resourceConnectorDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*client.Client)
+	c := m.(*providerMeta).client
 	var diags diag.Diagnostics
 
 	connectorID := d.Id()
 
-	if err := c.DeleteConnector(ctx, connectorID); err != nil {
-		// Check if the error indicates the connector was already deleted or not found
-		if strings.Contains(err.Error(), "Connector was deleted") ||
-			strings.Contains(err.Error(), "connector not found") {
-			// If the connector was already deleted, just remove it from state
-			d.SetId("")
-			return diags
+	onDestroy := d.Get("on_destroy").(string)
+	tflog.Info(ctx, "Destroying connector", map[string]interface{}{
+		"connector_id": connectorID,
+		"on_destroy":   onDestroy,
+	})
+
+	switch onDestroy {
+	case connectorOnDestroyAbandon:
+		// Only remove the connector from state
+	case connectorOnDestroyDisable:
+		// Keep the connector and its findings history, but stop it from scanning
+		enabled := false
+		if err := c.UpdateConnector(ctx, connectorID, client.UpdateConnectorPatch{Enabled: &enabled}); err != nil && !errors.Is(err, client.ErrNotFound) {
+			return diag.FromErr(fmt.Errorf("error disabling connector: %w", err))
+		}
+	default:
+		// Only deleting is protected, the other modes keep the connector
+		if d.Get("deletion_protection").(bool) {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Connector is protected from deletion",
+				Detail: fmt.Sprintf("Connector %s (%s) has deletion_protection enabled. Set deletion_protection = false and apply before destroying it.",
+					d.Get("name").(string), connectorID),
+			}}
+		}
+
+		if err := c.DeleteConnector(ctx, connectorID); err != nil {
+			// Check if the error indicates the connector was already deleted or not found
+			if errors.Is(err, client.ErrNotFound) {
+				// If the connector was already deleted, just remove it from state
+				d.SetId("")
+				return diags
+			}
+			return diag.FromErr(fmt.Errorf("error deleting connector: %w", err))
 		}
-		return diag.FromErr(fmt.Errorf("error deleting connector: %w", err))
 	}
 
 	d.SetId("")
@@ -0,0 +1,442 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/iancrichardson/terraform-provider-wiz/internal/client"
+)
+
+// outpostPlatforms lists the platforms that have a typed outpost configuration
+// block, with the GraphQL type of their config
+var outpostPlatforms = map[string]string{
+	"aws":   "OutpostAWSConfig",
+	"azure": "OutpostAzureConfig",
+}
+
+// outpostImportNamePrefix marks an import ID holding the name of the outpost
+const outpostImportNamePrefix = "name/"
+
+// outpostConfigSecretAttributes are the outpost configuration block attributes
+// holding secrets
+var outpostConfigSecretAttributes = []string{"client_secret"}
+
+func awsOutpostConfigSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The AWS region the outpost is deployed in",
+			},
+			"role_arn": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ARN of the IAM role Wiz assumes to operate the outpost",
+			},
+			"external_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The external ID required to assume the role",
+			},
+		},
+	}
+}
+
+func azureOutpostConfigSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The Azure region the outpost is deployed in",
+			},
+			"environment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "AzurePublicCloud",
+				Description: "The Azure cloud environment (e.g., AzurePublicCloud, AzureUSGovernmentCloud)",
+			},
+			"tenant_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The Azure tenant ID",
+			},
+			"subscription_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the subscription the outpost is deployed in",
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The application (client) ID Wiz uses to operate the outpost",
+			},
+			"client_secret": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				StateFunc:   hashSecretStateFunc,
+				Description: "The application client secret. Only its hash is stored in state",
+			},
+		},
+	}
+}
+
+func resourceOutpost() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceOutpostCreate,
+		ReadContext:   resourceOutpostRead,
+		UpdateContext: resourceOutpostUpdate,
+		DeleteContext: resourceOutpostDelete,
+		CustomizeDiff: resourceOutpostCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the outpost",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the outpost is enabled",
+			},
+			"self_managed": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Whether the outpost is deployed and operated in your own cloud account",
+			},
+			"aws": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				MaxItems:     1,
+				Description:  "Configuration of outposts deployed in AWS",
+				Elem:         awsOutpostConfigSchema(),
+				ExactlyOneOf: []string{"aws", "azure"},
+			},
+			"azure": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				MaxItems:     1,
+				Description:  "Configuration of outposts deployed in Azure",
+				Elem:         azureOutpostConfigSchema(),
+				ExactlyOneOf: []string{"aws", "azure"},
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The current status of the outpost",
+			},
+			"service_account_client_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The client ID of the outpost service account, only returned when the outpost is created",
+			},
+			"service_account_client_secret": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The client secret of the outpost service account, only returned when the outpost is created",
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceOutpostImport,
+		},
+	}
+}
+
+func resourceOutpostCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	var diags diag.Diagnostics
+
+	platform := outpostPlatform(d)
+
+	outpost, err := c.CreateOutpost(ctx, client.CreateOutpostInput{
+		Name:        d.Get("name").(string),
+		Enabled:     d.Get("enabled").(bool),
+		SelfManaged: d.Get("self_managed").(bool),
+		Config:      expandOutpostConfig(platform, d.Get(platform)),
+	})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error creating outpost: %w", err))
+	}
+
+	d.SetId(outpost.ID)
+
+	// Only keep the hashes of the secrets in the typed block
+	if err := d.Set(platform, hashBlockSecrets(d.Get(platform), outpostConfigSecretAttributes)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("status", outpost.Status); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The service account credentials are only returned on create, so keep them in state
+	if err := d.Set("service_account_client_id", outpost.ServiceAccount.ClientID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("service_account_client_secret", outpost.ServiceAccount.ClientSecret); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceOutpostRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	var diags diag.Diagnostics
+
+	outpost, err := c.GetOutpost(ctx, d.Id())
+	if err != nil {
+		// If the outpost was deleted outside of Terraform, remove it from state
+		if errors.Is(err, client.ErrNotFound) {
+			tflog.Warn(ctx, "Outpost not found, removing it from state", map[string]interface{}{
+				"outpost_id": d.Id(),
+			})
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(fmt.Errorf("error reading outpost: %w", err))
+	}
+
+	if err := setOutpostAttributes(d, outpost); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceOutpostUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+
+	patch := client.UpdateOutpostPatch{}
+
+	if d.HasChange("name") {
+		patch.Name = d.Get("name").(string)
+	}
+
+	if d.HasChange("enabled") {
+		enabled := d.Get("enabled").(bool)
+		patch.Enabled = &enabled
+	}
+
+	// The whole config is sent when any field of the block changed. A secret
+	// that did not change is only known by its hash and is left out.
+	platform := outpostPlatform(d)
+	if d.HasChange(platform) {
+		patch.Config = expandOutpostConfig(platform, d.Get(platform))
+	}
+
+	if patch.Name != "" || patch.Enabled != nil || patch.Config != nil {
+		if err := c.UpdateOutpost(ctx, d.Id(), patch); err != nil {
+			return diag.FromErr(fmt.Errorf("error updating outpost: %w", err))
+		}
+	}
+
+	if d.HasChange(platform) {
+		if err := d.Set(platform, hashBlockSecrets(d.Get(platform), outpostConfigSecretAttributes)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceOutpostRead(ctx, d, m)
+}
+
+func resourceOutpostDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).client
+	var diags diag.Diagnostics
+
+	if err := c.DeleteOutpost(ctx, d.Id()); err != nil {
+		// If the outpost was already deleted, just remove it from state
+		if !errors.Is(err, client.ErrNotFound) {
+			return diag.FromErr(fmt.Errorf("error deleting outpost: %w", err))
+		}
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+// resourceOutpostImport imports an outpost by ID, or by name given as
+// "name/<outpost name>"
+func resourceOutpostImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*providerMeta).client
+
+	outpostID := d.Id()
+	if name, ok := strings.CutPrefix(outpostID, outpostImportNamePrefix); ok {
+		id, err := findOutpostByName(ctx, c, name)
+		if err != nil {
+			return nil, err
+		}
+		outpostID = id
+	}
+
+	outpost, err := c.GetOutpost(ctx, outpostID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, fmt.Errorf("cannot import outpost %q: no outpost with this ID exists", outpostID)
+		}
+		return nil, fmt.Errorf("error getting outpost: %w", err)
+	}
+
+	d.SetId(outpost.ID)
+
+	if err := setOutpostAttributes(d, outpost); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceOutpostCustomizeDiff replaces the outpost when its platform changes,
+// since an outpost cannot be moved to another cloud
+func resourceOutpostCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	for platform := range outpostPlatforms {
+		o, n := d.GetChange(platform)
+		if (firstBlock(o) == nil) != (firstBlock(n) == nil) {
+			return d.ForceNew(platform)
+		}
+	}
+
+	return nil
+}
+
+// findOutpostByName returns the ID of the only outpost with the given name, or an
+// error if there is none or more than one
+func findOutpostByName(ctx context.Context, c *client.Client, name string) (string, error) {
+	outposts, err := c.ListOutposts(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("error looking up outpost: %w", err)
+	}
+
+	ids := make([]string, 0, len(outposts))
+	for _, outpost := range outposts {
+		ids = append(ids, outpost.ID)
+	}
+	return uniqueIDByName("outpost", name, ids)
+}
+
+// outpostPlatform returns the name of the typed configuration block in use
+func outpostPlatform(d *schema.ResourceData) string {
+	for platform := range outpostPlatforms {
+		if firstBlock(d.Get(platform)) != nil {
+			return platform
+		}
+	}
+	return ""
+}
+
+// expandOutpostConfig builds the OutpostConfigInput sent to the API from a typed
+// configuration block
+func expandOutpostConfig(platform string, v interface{}) map[string]interface{} {
+	raw := firstBlock(v)
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	config := map[string]interface{}{}
+	switch platform {
+	case "aws":
+		setIfNotEmpty(config, "region", raw["region"])
+		setIfNotEmpty(config, "roleArn", raw["role_arn"])
+		setIfNotEmpty(config, "externalId", raw["external_id"])
+	case "azure":
+		setIfNotEmpty(config, "region", raw["region"])
+		setIfNotEmpty(config, "environment", raw["environment"])
+		setIfNotEmpty(config, "tenantId", raw["tenant_id"])
+		setIfNotEmpty(config, "subscriptionId", raw["subscription_id"])
+		setIfNotEmpty(config, "clientId", raw["client_id"])
+		if !isSecretHash(raw["client_secret"]) {
+			setIfNotEmpty(config, "clientSecret", raw["client_secret"])
+		}
+	}
+
+	return map[string]interface{}{platform: config}
+}
+
+// flattenOutpostConfig builds the typed configuration block of an outpost. Fields
+// the API does not return, like secrets, are kept from the prior block.
+func flattenOutpostConfig(platform string, config *client.OutpostConfig, prior map[string]interface{}) []interface{} {
+	if config == nil {
+		config = &client.OutpostConfig{}
+	}
+	if prior == nil {
+		prior = map[string]interface{}{}
+	}
+
+	var block map[string]interface{}
+	switch platform {
+	case "aws":
+		block = map[string]interface{}{
+			"region":      stringOr(config.Region, prior["region"]),
+			"role_arn":    stringOr(config.RoleARN, prior["role_arn"]),
+			"external_id": stringOr(config.ExternalID, prior["external_id"]),
+		}
+	case "azure":
+		block = map[string]interface{}{
+			"region":          stringOr(config.Region, prior["region"]),
+			"environment":     stringOr(config.Environment, prior["environment"]),
+			"tenant_id":       stringOr(config.TenantID, prior["tenant_id"]),
+			"subscription_id": stringOr(config.SubscriptionID, prior["subscription_id"]),
+			"client_id":       stringOr(config.ClientID, prior["client_id"]),
+			"client_secret":   hashSecretStateFunc(prior["client_secret"]),
+		}
+	}
+
+	return []interface{}{block}
+}
+
+// setOutpostAttributes sets the attributes of a wiz_outpost resource or data
+// source from an outpost returned by the API
+func setOutpostAttributes(d *schema.ResourceData, outpost *client.Outpost) error {
+	if err := d.Set("name", outpost.Name); err != nil {
+		return err
+	}
+	if err := d.Set("enabled", outpost.Enabled); err != nil {
+		return err
+	}
+	if err := d.Set("self_managed", outpost.SelfManaged); err != nil {
+		return err
+	}
+	if err := d.Set("status", outpost.Status); err != nil {
+		return err
+	}
+
+	typename := ""
+	if outpost.Config != nil {
+		typename = outpost.Config.Typename
+	}
+	for platform, platformTypename := range outpostPlatforms {
+		var block []interface{}
+		if typename == platformTypename {
+			block = flattenOutpostConfig(platform, outpost.Config, firstBlock(d.Get(platform)))
+		}
+		if err := d.Set(platform, block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}